@@ -0,0 +1,121 @@
+// Copyright 2013 Dario Castañé. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mergo
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Reason tells why a Change was made.
+type Reason string
+
+// Reasons reported in a Change.
+const (
+	// ReasonFill means dst was empty and was set to src's value.
+	ReasonFill Reason = "fill"
+	// ReasonOverwrite means dst's value was overridden by src's.
+	ReasonOverwrite Reason = "overwrite"
+	// ReasonAppend means src's elements were appended to dst's.
+	ReasonAppend Reason = "append"
+	// ReasonTransform means a transformer changed dst's value.
+	ReasonTransform Reason = "transform"
+	// ReasonResolve means dst was set to the value returned by a resolver.
+	ReasonResolve Reason = "resolve"
+	// ReasonMerger means dst's MergeFrom method changed its value.
+	ReasonMerger Reason = "merger"
+)
+
+// Change describes an assignment done while merging.
+type Change struct {
+	// Path locates the assigned value from the root of dst, e.g.
+	// Network.Port, Labels["env"] or Servers[2].
+	Path string
+	// Old and New are the values before and after the assignment.
+	Old, New interface{}
+	Reason   Reason
+	// Layer is the index of the src the change comes from in MergeAll,
+	// zero otherwise.
+	Layer int
+}
+
+// WithChanges will make merge append to changes every assignment it does on dst.
+func WithChanges(changes *[]Change) func(*Config) {
+	return func(config *Config) {
+		config.changes = changes
+	}
+}
+
+// WithDryRun will make merge leave dst untouched, doing its assignments on a deep copy of it
+// instead. Use it with WithChanges to preview what a merge would do.
+func WithDryRun(config *Config) {
+	config.DryRun = true
+}
+
+// dryRunTarget returns the value to merge into instead of dst if
+// config.DryRun is set: a settable deep copy of it.
+func dryRunTarget(dst reflect.Value, config *Config) reflect.Value {
+	if !config.DryRun {
+		return dst
+	}
+	c := reflect.New(dst.Type()).Elem()
+	c.Set(deepCopy(dst, make(map[visit]reflect.Value)))
+	return c
+}
+
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func indexPath(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
+
+func keyPath(path string, key reflect.Value) string {
+	if key.Kind() == reflect.Interface {
+		key = key.Elem()
+	}
+	if key.Kind() == reflect.String {
+		return fmt.Sprintf("%s[%q]", path, key.String())
+	}
+	return fmt.Sprintf("%s[%v]", path, key)
+}
+
+func interfaceOf(v reflect.Value) interface{} {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+func record(config *Config, path string, old, new reflect.Value, reason Reason) {
+	*config.changes = append(*config.changes, Change{
+		Path:   path,
+		Old:    interfaceOf(old),
+		New:    interfaceOf(new),
+		Reason: reason,
+		Layer:  config.layer,
+	})
+}
+
+// set assigns v to dst, recording the change if required by config.
+func set(dst, v reflect.Value, path string, reason Reason, config *Config) {
+	if config.changes != nil {
+		record(config, path, dst, v, reason)
+	}
+	dst.Set(v)
+}
+
+// setMapIndex sets m's element at key to v, recording the change if
+// required by config.
+func setMapIndex(m, key, v reflect.Value, path string, reason Reason, config *Config) {
+	if config.changes != nil {
+		record(config, path, m.MapIndex(key), v, reason)
+	}
+	m.SetMapIndex(key, v)
+}
@@ -0,0 +1,129 @@
+package mergo
+
+import (
+	"reflect"
+	"testing"
+)
+
+type networkConfig struct {
+	Protocol string
+	Port     int
+}
+
+type serviceConfig struct {
+	Network networkConfig
+	Labels  map[string]string
+	Hosts   []string
+}
+
+func TestMergeWithChanges(t *testing.T) {
+	dst := serviceConfig{
+		Network: networkConfig{Protocol: "tcp"},
+		Labels:  map[string]string{"app": "foo", "env": "dev"},
+		Hosts:   []string{"a"},
+	}
+	src := serviceConfig{
+		Network: networkConfig{Protocol: "udp", Port: 80},
+		Labels:  map[string]string{"env": "prod", "team": "bar"},
+		Hosts:   []string{"b"},
+	}
+	var changes []Change
+	if err := Merge(&dst, src, WithOverride, WithChanges(&changes)); err != nil {
+		t.Fatal(err)
+	}
+	expected := []Change{
+		{Path: "Network.Protocol", Old: "tcp", New: "udp", Reason: ReasonOverwrite},
+		{Path: "Network.Port", Old: 0, New: 80, Reason: ReasonFill},
+		{Path: "Hosts", Old: []string{"a"}, New: []string{"b"}, Reason: ReasonOverwrite},
+	}
+	labels := map[string]Change{
+		`Labels["env"]`:  {Path: `Labels["env"]`, Old: "dev", New: "prod", Reason: ReasonOverwrite},
+		`Labels["team"]`: {Path: `Labels["team"]`, Old: nil, New: "bar", Reason: ReasonFill},
+	}
+	var got []Change
+	for _, c := range changes {
+		if l, ok := labels[c.Path]; ok {
+			if !reflect.DeepEqual(c, l) {
+				t.Errorf("got %+v expected %+v", c, l)
+			}
+			delete(labels, c.Path)
+			continue
+		}
+		got = append(got, c)
+	}
+	if len(labels) != 0 {
+		t.Errorf("missing changes: %+v", labels)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Test failed:\ngot  :\n%+v\n\nwant :\n%+v\n\n", got, expected)
+	}
+}
+
+func TestMapWithChanges(t *testing.T) {
+	dst := map[string]interface{}{"protocol": "tcp"}
+	var changes []Change
+	if err := Map(&dst, networkConfig{Protocol: "udp", Port: 80}, WithChanges(&changes)); err != nil {
+		t.Fatal(err)
+	}
+	expected := []Change{
+		{Path: `["port"]`, Old: nil, New: 80, Reason: ReasonFill},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("Test failed:\ngot  :\n%+v\n\nwant :\n%+v\n\n", changes, expected)
+	}
+}
+
+func TestMergeSliceChanges(t *testing.T) {
+	dst := sliceTest{[]int{1}}
+	var changes []Change
+	if err := Merge(&dst, sliceTest{[]int{2}}, WithChanges(&changes)); err != nil {
+		t.Fatal(err)
+	}
+	expected := []Change{{Path: "S", Old: []int{1}, New: []int{1, 2}, Reason: ReasonAppend}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("Test failed:\ngot  :\n%+v\n\nwant :\n%+v\n\n", changes, expected)
+	}
+}
+
+func TestMergeWithDryRun(t *testing.T) {
+	dst := serviceConfig{
+		Network: networkConfig{Protocol: "tcp"},
+		Labels:  map[string]string{"env": "dev"},
+		Hosts:   []string{"a"},
+	}
+	src := serviceConfig{
+		Network: networkConfig{Port: 80},
+		Labels:  map[string]string{"env": "prod", "team": "bar"},
+		Hosts:   []string{"b"},
+	}
+	var changes []Change
+	if err := Merge(&dst, src, WithOverride, WithAppendSlice, WithDryRun, WithChanges(&changes)); err != nil {
+		t.Fatal(err)
+	}
+	expected := serviceConfig{
+		Network: networkConfig{Protocol: "tcp"},
+		Labels:  map[string]string{"env": "dev"},
+		Hosts:   []string{"a"},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("dst changed by dry run:\ngot  :\n%+v\n\nwant :\n%+v\n\n", dst, expected)
+	}
+	if len(changes) != 4 {
+		t.Fatalf("expected 4 changes, got %+v", changes)
+	}
+}
+
+func TestMapWithDryRun(t *testing.T) {
+	dst := simpleTest{}
+	var changes []Change
+	if err := Map(&dst, map[string]interface{}{"value": 42}, WithDryRun, WithChanges(&changes)); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Value != 0 {
+		t.Fatalf("dst changed by dry run: dst.Value(%d)", dst.Value)
+	}
+	expected := []Change{{Path: "Value", Old: 0, New: 42, Reason: ReasonFill}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("Test failed:\ngot  :\n%+v\n\nwant :\n%+v\n\n", changes, expected)
+	}
+}
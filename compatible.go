@@ -0,0 +1,118 @@
+// Copyright 2013 Dario Castañé. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mergo
+
+import (
+	"reflect"
+	"strings"
+)
+
+// CompatibilityError is returned by merge using WithCompatibleStructs when
+// non-empty src fields couldn't be merged into dst. The other fields are
+// merged anyway.
+type CompatibilityError struct {
+	// Unmatched are the paths of src fields without a matching dst field.
+	Unmatched []string
+	// Incompatible are the errors of src fields whose matching dst field
+	// has a type they can't be merged into.
+	Incompatible []*MergeError
+}
+
+func (e *CompatibilityError) Error() string {
+	var fields []string
+	for _, path := range e.Unmatched {
+		fields = append(fields, path+" (unmatched)")
+	}
+	for _, err := range e.Incompatible {
+		fields = append(fields, err.Error())
+	}
+	return "mergo: src fields not merged: " + strings.Join(fields, ", ")
+}
+
+// WithCompatibleStructs will make merge accept dst and src of different struct types, like a
+// request DTO and a domain struct or two versions of a configuration. src fields are merged
+// into the dst fields with the same key, as Map names them: by name, or by tag when using
+// WithTagName. Nested structs, and pointers to them, of different types are merged the same
+// way. Non-empty src fields without a matching dst field, or whose dst field has an
+// incompatible type, are reported in a *CompatibilityError after merging the others.
+func WithCompatibleStructs(config *Config) {
+	config.CompatibleStructs = true
+}
+
+// compatibleTypes reports whether values of different types dt and st can be
+// merged field by field using WithCompatibleStructs.
+func compatibleTypes(dt, st reflect.Type, config *Config) bool {
+	if !config.CompatibleStructs || dt.Kind() != st.Kind() {
+		return false
+	}
+	return indirectType(dt).Kind() == reflect.Struct && indirectType(st).Kind() == reflect.Struct
+}
+
+// mergeCompatible merges src into dst, structs or pointers to structs of
+// different types, matching their fields by key.
+func mergeCompatible(dst, src reflect.Value, visited map[visit]bool, depth int, path string, config *Config) error {
+	if src.Kind() == reflect.Ptr {
+		if src.IsNil() {
+			return nil
+		}
+		if dst.IsNil() {
+			if !dst.CanSet() {
+				return nil
+			}
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		v := visit{dst.Pointer(), src.Pointer(), dst.Type()}
+		if visited[v] {
+			return nil
+		}
+		visited[v] = true
+		dst, src = dst.Elem(), src.Elem()
+	}
+	dstFields := make(map[string]reflect.StructField)
+	for _, field := range mapFields(dst.Type(), config) {
+		key, _, _ := mapKey(field, config)
+		dstFields[key] = field
+	}
+	for _, srcField := range mapFields(src.Type(), config) {
+		srcElement := fieldByIndex(src, srcField.Index, false)
+		if !srcElement.IsValid() || isEmpty(srcElement, config) && !config.OverwriteWithEmptyValue {
+			continue
+		}
+		key, _, _ := mapKey(srcField, config)
+		dstField, ok := dstFields[key]
+		if !ok {
+			config.compatibility.Unmatched = append(config.compatibility.Unmatched, fieldPath(path, srcField.Name))
+			continue
+		}
+		c, skip := fieldConfig(dstField, config)
+		if skip {
+			continue
+		}
+		dstElement := fieldByIndex(dst, dstField.Index, true)
+		if !dstElement.IsValid() {
+			continue
+		}
+		elementPath := fieldPath(path, dstField.Name)
+		if dstField.Type != srcField.Type && !compatibleTypes(dstField.Type, srcField.Type, config) {
+			err := &MergeError{elementPath, dstField.Type, srcField.Type, ErrDifferentArgumentsTypes}
+			config.compatibility.Incompatible = append(config.compatibility.Incompatible, err)
+			continue
+		}
+		if err := deepMerge(dstElement, srcElement, visited, depth+1, elementPath, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compatibilityError returns the src fields not merged using
+// WithCompatibleStructs, if any.
+func compatibilityError(config *Config) error {
+	e := config.compatibility
+	if e == nil || len(e.Unmatched)+len(e.Incompatible) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,95 @@
+package mergo
+
+import (
+	"reflect"
+	"testing"
+)
+
+type endpointV1 struct {
+	Host    string
+	Port    string
+	Timeout int
+}
+
+type serverV1 struct {
+	Name     string
+	Endpoint *endpointV1
+	Debug    bool
+}
+
+type endpointV2 struct {
+	Host    string
+	Port    int
+	Timeout int
+	TLS     bool
+}
+
+type serverV2 struct {
+	Name     string `json:"name"`
+	Endpoint *endpointV2
+	Replicas int
+}
+
+func TestMergeWithCompatibleStructs(t *testing.T) {
+	src := serverV1{
+		Name:     "api",
+		Endpoint: &endpointV1{Host: "api.example.com", Port: "443", Timeout: 30},
+		Debug:    true,
+	}
+	dst := serverV2{Endpoint: &endpointV2{Host: "api.local", TLS: true}, Replicas: 2}
+	if err := Merge(&dst, src); err != ErrDifferentArgumentsTypes {
+		t.Fatalf("expected ErrDifferentArgumentsTypes without WithCompatibleStructs, got %v", err)
+	}
+	err := Merge(&dst, src, WithCompatibleStructs)
+	compatErr, ok := err.(*CompatibilityError)
+	if !ok {
+		t.Fatalf("expected a *CompatibilityError, got %v", err)
+	}
+	if expected := []string{"Debug"}; !reflect.DeepEqual(compatErr.Unmatched, expected) {
+		t.Fatalf("unmatched fields: got %v expected %v", compatErr.Unmatched, expected)
+	}
+	if len(compatErr.Incompatible) != 1 || compatErr.Incompatible[0].Path != "Endpoint.Port" {
+		t.Fatalf("incompatible fields: got %v expected Endpoint.Port", compatErr.Incompatible)
+	}
+	expected := serverV2{
+		Name:     "api",
+		Endpoint: &endpointV2{Host: "api.local", Timeout: 30, TLS: true},
+		Replicas: 2,
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("got %+v %+v expected %+v %+v", dst, dst.Endpoint, expected, expected.Endpoint)
+	}
+}
+
+func TestMergeWithCompatibleStructsByTag(t *testing.T) {
+	type request struct {
+		ServerName string `json:"name"`
+		Replicas   int
+	}
+	var dst serverV2
+	src := request{ServerName: "api", Replicas: 3}
+	if err := Merge(&dst, src, WithCompatibleStructs, WithTagName("json")); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Name != "api" || dst.Replicas != 3 || dst.Endpoint != nil {
+		t.Fatalf("fields not matched by tag: %+v", dst)
+	}
+}
+
+func TestMergeWithCompatibleStructsOverwriteWithEmptyValue(t *testing.T) {
+	type listener struct {
+		Port    int
+		Enabled bool
+	}
+	type listenerRequest struct {
+		Port    int
+		Enabled bool
+	}
+	dst := listener{Port: 8, Enabled: true}
+	if err := Merge(&dst, listenerRequest{}, WithCompatibleStructs, WithOverwriteWithEmptyValue); err != nil {
+		t.Fatal(err)
+	}
+	if dst != (listener{}) {
+		t.Fatalf("empty values didn't overwrite dst: %+v", dst)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2013 Dario Castañé. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mergo
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Conflict describes a value set on both dst and src to different values.
+type Conflict struct {
+	// Path locates the value from the root of dst, as in Change.
+	Path string
+	// Dst and Src are the conflicting values.
+	Dst, Src interface{}
+}
+
+// ConflictError is returned by merge in strict mode when dst and src
+// hold different non-empty values for the same fields, sorted by path. dst has been merged
+// anyway with the values that don't conflict: use WithDryRun to check for
+// conflicts leaving it untouched.
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	paths := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		paths[i] = fmt.Sprintf("%s (%v != %v)", c.Path, c.Dst, c.Src)
+	}
+	return "mergo: conflicting values on " + strings.Join(paths, ", ")
+}
+
+// WithStrict will make merge report in a *ConflictError every field where dst and src hold
+// different non-empty values, leaving those fields untouched. Pointers and map values are
+// merged recursively, as with the deep tag, so conflicts are found on their fields. Slices are
+// compared as a whole, unless a strategy is chosen for them, e.g. with WithSliceStrategy,
+// WithAppendSlice, WithSliceKey or a slice tag, in which case they are combined by it. Fields
+// that don't conflict are merged into dst even if a *ConflictError is returned.
+func WithStrict(config *Config) {
+	config.Strict = true
+}
+
+// WithResolver will make merge call resolver for every field where both dst and src hold
+// non-empty values that can't be merged recursively, setting dst to the value it returns,
+// or leaving dst untouched if it returns an invalid reflect.Value. Pointers and map values
+// are merged recursively, as with the deep tag, so resolver is called on their fields. As in
+// strict mode, slices are passed to resolver as a whole unless a strategy is chosen for them.
+func WithResolver(resolver func(path string, dst, src reflect.Value) (reflect.Value, error)) func(*Config) {
+	return func(config *Config) {
+		config.Resolver = resolver
+	}
+}
+
+// resolve merges src into dst, both non-empty values that can't be merged
+// recursively: dst is set to src if take is true, unless there is a resolver
+// or in strict mode they conflict.
+func resolve(dst, src reflect.Value, path string, take bool, config *Config) error {
+	if config.Resolver != nil {
+		v, err := config.Resolver(path, dst, src)
+		if err != nil {
+			return newMergeError(path, dst, src, err)
+		}
+		if !v.IsValid() || !dst.CanSet() {
+			return nil
+		}
+		if !v.Type().AssignableTo(dst.Type()) {
+			return newMergeError(path, dst, v, ErrDifferentArgumentsTypes)
+		}
+		if !reflect.DeepEqual(dst.Interface(), v.Interface()) {
+			set(dst, v, path, ReasonResolve, config)
+		}
+		return nil
+	}
+	if config.Strict {
+		if !reflect.DeepEqual(dst.Interface(), src.Interface()) {
+			*config.conflicts = append(*config.conflicts, Conflict{path, dst.Interface(), src.Interface()})
+		}
+		return nil
+	}
+	if take && dst.CanSet() {
+		set(dst, copyOf(src, config), path, ReasonOverwrite, config)
+	}
+	return nil
+}
+
+// conflictError returns the conflicts found while merging with config, if any.
+func conflictError(config *Config) error {
+	if config.conflicts == nil || len(*config.conflicts) == 0 {
+		return nil
+	}
+	conflicts := *config.conflicts
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		return conflicts[i].Path < conflicts[j].Path
+	})
+	return &ConflictError{conflicts}
+}
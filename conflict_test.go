@@ -0,0 +1,167 @@
+package mergo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMergeWithStrict(t *testing.T) {
+	dst := serviceConfig{
+		Network: networkConfig{Protocol: "tcp", Port: 80},
+		Labels:  map[string]string{"env": "dev", "app": "foo"},
+	}
+	src := serviceConfig{
+		Network: networkConfig{Protocol: "tcp", Port: 8080},
+		Labels:  map[string]string{"env": "prod", "team": "bar"},
+	}
+	err := Merge(&dst, src, WithStrict)
+	conflictErr, ok := err.(*ConflictError)
+	if !ok {
+		t.Fatalf("expected a *ConflictError, got %v", err)
+	}
+	expected := []Conflict{
+		{Path: `Labels["env"]`, Dst: "dev", Src: "prod"},
+		{Path: "Network.Port", Dst: 80, Src: 8080},
+	}
+	if !reflect.DeepEqual(conflictErr.Conflicts, expected) {
+		t.Fatalf("Test failed:\ngot  :\n%+v\n\nwant :\n%+v\n\n", conflictErr.Conflicts, expected)
+	}
+	if dst.Network.Port != 80 || dst.Labels["env"] != "dev" {
+		t.Fatalf("conflicting values changed: %+v", dst)
+	}
+	if dst.Labels["team"] != "bar" {
+		t.Fatalf("non conflicting values not merged: %+v", dst)
+	}
+}
+
+func TestMergeWithStrictAndOverride(t *testing.T) {
+	a, b := 1, 2
+	dst := struct{ P *int }{&a}
+	src := struct{ P *int }{&b}
+	err := Merge(&dst, src, WithStrict, WithOverride)
+	conflictErr, ok := err.(*ConflictError)
+	if !ok {
+		t.Fatalf("expected a *ConflictError, got %v", err)
+	}
+	if len(conflictErr.Conflicts) != 1 || conflictErr.Conflicts[0].Path != "P" {
+		t.Fatalf("unexpected conflicts: %+v", conflictErr.Conflicts)
+	}
+	if dst.P != &a || a != 1 {
+		t.Fatalf("conflicting pointer changed")
+	}
+}
+
+func TestMergeWithStrictNoConflicts(t *testing.T) {
+	dst := networkConfig{Protocol: "tcp"}
+	src := networkConfig{Protocol: "tcp", Port: 80}
+	if err := Merge(&dst, src, WithStrict, WithOverride); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Port != 80 {
+		t.Fatalf("b not merged in properly: dst.Port(%d) != src.Port(%d)", dst.Port, src.Port)
+	}
+}
+
+func TestMergeWithStrictSlices(t *testing.T) {
+	for _, opts := range [][]func(*Config){{WithStrict}, {WithStrict, WithOverride}} {
+		dst := serviceConfig{Hosts: []string{"a"}}
+		src := serviceConfig{Hosts: []string{"b"}}
+		err := Merge(&dst, src, opts...)
+		conflictErr, ok := err.(*ConflictError)
+		if !ok || len(conflictErr.Conflicts) != 1 || conflictErr.Conflicts[0].Path != "Hosts" {
+			t.Fatalf("expected a conflict on Hosts, got %v", err)
+		}
+		if !reflect.DeepEqual(dst.Hosts, []string{"a"}) {
+			t.Fatalf("conflicting slice changed: %v", dst.Hosts)
+		}
+	}
+	dst := serviceConfig{Hosts: []string{"a"}}
+	src := serviceConfig{Hosts: []string{"b"}}
+	if err := Merge(&dst, src, WithStrict, WithSliceStrategy(SliceUnion)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dst.Hosts, []string{"a", "b"}) {
+		t.Fatalf("slices not combined by their strategy: %v", dst.Hosts)
+	}
+}
+
+func TestMergeWithResolverSlices(t *testing.T) {
+	dst := serviceConfig{Hosts: []string{"a"}}
+	src := serviceConfig{Hosts: []string{"b"}}
+	var paths []string
+	keep := func(path string, dst, src reflect.Value) (reflect.Value, error) {
+		paths = append(paths, path)
+		return reflect.Value{}, nil
+	}
+	if err := Merge(&dst, src, WithResolver(keep)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(paths, []string{"Hosts"}) || !reflect.DeepEqual(dst.Hosts, []string{"a"}) {
+		t.Fatalf("resolver not called on slice: %v %v", paths, dst.Hosts)
+	}
+}
+
+func TestMergeWithStrictSortsConflicts(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		dst := map[string]int{"a": 1, "l": 1, "c": 1, "x": 1}
+		src := map[string]int{"a": 2, "l": 2, "c": 2, "x": 2}
+		err := Merge(&dst, src, WithStrict)
+		if expected := `mergo: conflicting values on ["a"] (1 != 2), ["c"] (1 != 2), ["l"] (1 != 2), ["x"] (1 != 2)`; err == nil || err.Error() != expected {
+			t.Fatalf("got %v expected %s", err, expected)
+		}
+	}
+}
+
+func TestMergeWithResolver(t *testing.T) {
+	dst := serviceConfig{
+		Network: networkConfig{Protocol: "tcp", Port: 80},
+		Labels:  map[string]string{"env": "dev"},
+	}
+	src := serviceConfig{
+		Network: networkConfig{Protocol: "udp", Port: 8080},
+		Labels:  map[string]string{"env": "prod"},
+	}
+	var paths []string
+	max := func(path string, dst, src reflect.Value) (reflect.Value, error) {
+		paths = append(paths, path)
+		switch dst.Kind() {
+		case reflect.Int:
+			if src.Int() > dst.Int() {
+				return src, nil
+			}
+			return dst, nil
+		case reflect.String:
+			return reflect.ValueOf(dst.String() + "+" + src.String()), nil
+		}
+		return reflect.Value{}, nil
+	}
+	if err := Merge(&dst, src, WithResolver(max)); err != nil {
+		t.Fatal(err)
+	}
+	expected := serviceConfig{
+		Network: networkConfig{Protocol: "tcp+udp", Port: 8080},
+		Labels:  map[string]string{"env": "dev+prod"},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Test failed:\ngot  :\n%+v\n\nwant :\n%+v\n\n", dst, expected)
+	}
+	if !reflect.DeepEqual(paths, []string{"Network.Protocol", "Network.Port", `Labels["env"]`}) {
+		t.Fatalf("unexpected resolved paths: %v", paths)
+	}
+}
+
+func TestMergeWithResolverError(t *testing.T) {
+	dst := networkConfig{Port: 80}
+	src := networkConfig{Port: 8080}
+	fail := func(path string, dst, src reflect.Value) (reflect.Value, error) {
+		return reflect.Value{}, ErrNotSupported
+	}
+	err := Merge(&dst, src, WithResolver(fail))
+	if !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mergeErr, ok := err.(*MergeError); !ok || mergeErr.Path != "Port" {
+		t.Fatalf("expected a *MergeError on Port, got %v", err)
+	}
+}
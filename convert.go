@@ -0,0 +1,187 @@
+// Copyright 2013 Dario Castañé. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mergo
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// WithWeakTypes will make Map convert values of maps to the type of the struct fields they are
+// mapped to when they differ: between numeric types, checking for overflows and lost
+// fractions, between strings and numbers, from strings to booleans, and from strings and
+// numbers to time.Duration. Strings are parsed with time.ParseDuration, while numbers count
+// nanoseconds. Conversions that aren't possible without loss fail with ErrInvalidConversion.
+func WithWeakTypes(config *Config) {
+	config.WeakTypes = true
+}
+
+// isWeakKind reports whether values can be weakly converted to type t.
+func isWeakKind(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func conversionError(v reflect.Value, t reflect.Type, reason string) error {
+	return fmt.Errorf("%w: %v %s %s", ErrInvalidConversion, v, reason, t)
+}
+
+// weakConvert returns v converted to type t, as described in WithWeakTypes.
+func weakConvert(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	if v.Type().AssignableTo(t) {
+		return v, nil
+	}
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Zero(t), nil
+		}
+		v = v.Elem()
+	}
+	r := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Bool:
+		switch v.Kind() {
+		case reflect.Bool:
+			r.SetBool(v.Bool())
+		case reflect.String:
+			b, err := strconv.ParseBool(v.String())
+			if err != nil {
+				return r, conversionError(v, t, "is not a valid")
+			}
+			r.SetBool(b)
+		default:
+			return r, conversionError(v, t, "can't be converted to")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if t == durationType && v.Kind() == reflect.String {
+			d, err := time.ParseDuration(v.String())
+			if err != nil {
+				return r, conversionError(v, t, "is not a valid")
+			}
+			r.SetInt(int64(d))
+			return r, nil
+		}
+		var i int64
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i = v.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if v.Uint() > math.MaxInt64 {
+				return r, conversionError(v, t, "overflows")
+			}
+			i = int64(v.Uint())
+		case reflect.Float32, reflect.Float64:
+			f := v.Float()
+			if f != math.Trunc(f) {
+				return r, conversionError(v, t, "is not an integer to convert to")
+			}
+			if f < math.MinInt64 || f >= math.MaxInt64 {
+				return r, conversionError(v, t, "overflows")
+			}
+			i = int64(f)
+		case reflect.String:
+			var err error
+			if i, err = strconv.ParseInt(v.String(), 0, 64); err != nil {
+				return r, conversionError(v, t, "is not a valid")
+			}
+		default:
+			return r, conversionError(v, t, "can't be converted to")
+		}
+		if r.OverflowInt(i) {
+			return r, conversionError(v, t, "overflows")
+		}
+		r.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if v.Int() < 0 {
+				return r, conversionError(v, t, "overflows")
+			}
+			u = uint64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			u = v.Uint()
+		case reflect.Float32, reflect.Float64:
+			f := v.Float()
+			if f != math.Trunc(f) {
+				return r, conversionError(v, t, "is not an integer to convert to")
+			}
+			if f < 0 || f >= math.MaxUint64 {
+				return r, conversionError(v, t, "overflows")
+			}
+			u = uint64(f)
+		case reflect.String:
+			var err error
+			if u, err = strconv.ParseUint(v.String(), 0, 64); err != nil {
+				return r, conversionError(v, t, "is not a valid")
+			}
+		default:
+			return r, conversionError(v, t, "can't be converted to")
+		}
+		if r.OverflowUint(u) {
+			return r, conversionError(v, t, "overflows")
+		}
+		r.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f = float64(v.Int())
+			if int64(f) != v.Int() {
+				return r, conversionError(v, t, "loses precision as")
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			f = float64(v.Uint())
+			if uint64(f) != v.Uint() {
+				return r, conversionError(v, t, "loses precision as")
+			}
+		case reflect.Float32, reflect.Float64:
+			f = v.Float()
+		case reflect.String:
+			var err error
+			if f, err = strconv.ParseFloat(v.String(), 64); err != nil {
+				return r, conversionError(v, t, "is not a valid")
+			}
+		default:
+			return r, conversionError(v, t, "can't be converted to")
+		}
+		if r.OverflowFloat(f) {
+			return r, conversionError(v, t, "overflows")
+		}
+		if t.Kind() == reflect.Float32 && float64(float32(f)) != f {
+			return r, conversionError(v, t, "loses precision as")
+		}
+		r.SetFloat(f)
+	case reflect.String:
+		switch v.Kind() {
+		case reflect.String:
+			r.SetString(v.String())
+		case reflect.Bool:
+			r.SetString(strconv.FormatBool(v.Bool()))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			r.SetString(strconv.FormatInt(v.Int(), 10))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			r.SetString(strconv.FormatUint(v.Uint(), 10))
+		case reflect.Float32, reflect.Float64:
+			r.SetString(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()))
+		default:
+			return r, conversionError(v, t, "can't be converted to")
+		}
+	default:
+		return r, conversionError(v, t, "can't be converted to")
+	}
+	return r, nil
+}
@@ -0,0 +1,79 @@
+package mergo
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type weakTypesTest struct {
+	Port    uint16
+	Ratio   float32
+	Retries int
+	Enabled bool
+	Name    string
+	Timeout time.Duration
+	Backoff time.Duration
+}
+
+func TestMapWithWeakTypes(t *testing.T) {
+	src := map[string]interface{}{
+		"port":    float64(8080),
+		"ratio":   "0.5",
+		"retries": "3",
+		"enabled": "true",
+		"name":    42,
+		"timeout": "1m30s",
+		"backoff": float64(time.Second),
+	}
+	var dst weakTypesTest
+	if err := Map(&dst, src); err == nil {
+		t.Fatalf("expected a type mismatch error without WithWeakTypes")
+	}
+	dst = weakTypesTest{}
+	if err := Map(&dst, src, WithWeakTypes); err != nil {
+		t.Fatal(err)
+	}
+	expected := weakTypesTest{
+		Port:    8080,
+		Ratio:   0.5,
+		Retries: 3,
+		Enabled: true,
+		Name:    "42",
+		Timeout: 90 * time.Second,
+		Backoff: time.Second,
+	}
+	if dst != expected {
+		t.Fatalf("Test failed:\ngot  :\n%+v\n\nwant :\n%+v\n\n", dst, expected)
+	}
+}
+
+func TestMapWithWeakTypesErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		src  map[string]interface{}
+		path string
+	}{
+		{"overflow", map[string]interface{}{"port": float64(70000)}, "Port"},
+		{"negative", map[string]interface{}{"port": -1}, "Port"},
+		{"fraction", map[string]interface{}{"retries": 1.5}, "Retries"},
+		{"not a number", map[string]interface{}{"retries": "three"}, "Retries"},
+		{"float32 precision", map[string]interface{}{"ratio": 0.1}, "Ratio"},
+		{"float32 integer precision", map[string]interface{}{"ratio": 16777217}, "Ratio"},
+		{"not a bool", map[string]interface{}{"enabled": "maybe"}, "Enabled"},
+		{"not a duration", map[string]interface{}{"timeout": "soon"}, "Timeout"},
+		{"impossible", map[string]interface{}{"name": []int{1}}, "Name"},
+	}
+	for _, c := range cases {
+		var dst weakTypesTest
+		err := Map(&dst, c.src, WithWeakTypes)
+		if !errors.Is(err, ErrInvalidConversion) {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+			continue
+		}
+		var mergeErr *MergeError
+		if !errors.As(err, &mergeErr) || mergeErr.Path != c.path {
+			t.Errorf("%s: expected a *MergeError on %s, got %v", c.name, c.path, err)
+		}
+	}
+}
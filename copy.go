@@ -0,0 +1,110 @@
+// Copyright 2013 Dario Castañé. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mergo
+
+import (
+	"reflect"
+)
+
+// Returns a copy of v sharing no pointers, maps or slices with it.
+// The copies argument maps values already copied, keyed like visited
+// merges, so shared references and cycles are preserved in the copy.
+// Unexported fields are copied as they are.
+func deepCopy(v reflect.Value, copies map[visit]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		key := visit{v.Pointer(), 0, v.Type()}
+		if c, ok := copies[key]; ok {
+			return c
+		}
+		c := reflect.New(v.Type().Elem())
+		copies[key] = c
+		c.Elem().Set(deepCopy(v.Elem(), copies))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem(), copies))
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		key := visit{v.Pointer(), 0, v.Type()}
+		if c, ok := copies[key]; ok {
+			return c
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		copies[key] = c
+		for _, k := range v.MapKeys() {
+			c.SetMapIndex(k, deepCopy(v.MapIndex(k), copies))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		key := visit{v.Pointer(), uintptr(v.Len()), v.Type()}
+		if c, ok := copies[key]; ok {
+			return c
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		copies[key] = c
+		for i, n := 0, v.Len(); i < n; i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), copies))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i, n := 0, v.Len(); i < n; i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), copies))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i, n := 0, v.NumField(); i < n; i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i), copies))
+			}
+		}
+		return c
+	}
+	return v
+}
+
+// copyOf returns the value to assign from v: a deep copy of it if
+// config.DeepCopy is set, or v itself otherwise.
+func copyOf(v reflect.Value, config *Config) reflect.Value {
+	if !config.DeepCopy {
+		return v
+	}
+	return deepCopy(v, config.copies)
+}
+
+// Clone sets dst to a deep copy of src: unlike merging into an empty value,
+// pointers, maps and slices are allocated anew instead of being shared
+// with src, so later changes to one don't leak into the other.
+// dst must be a pointer to a struct or map of the same type as src.
+// Unexported (private) fields are copied as they are.
+func Clone(dst, src interface{}) error {
+	var (
+		vDst, vSrc reflect.Value
+		err        error
+	)
+	if vDst, vSrc, err = resolveValues(dst, src); err != nil {
+		return err
+	}
+	if vDst.Type() != vSrc.Type() {
+		return ErrDifferentArgumentsTypes
+	}
+	vDst.Set(deepCopy(vSrc, make(map[visit]reflect.Value)))
+	return nil
+}
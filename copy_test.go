@@ -0,0 +1,92 @@
+package mergo
+
+import (
+	"reflect"
+	"testing"
+)
+
+type cloneTest struct {
+	Name   string
+	Ptr    *simpleTest
+	Labels map[string]string
+	Values []int
+	Nested []*simpleTest
+	Any    interface{}
+	hidden int
+}
+
+func TestClone(t *testing.T) {
+	src := cloneTest{
+		Name:   "foo",
+		Ptr:    &simpleTest{1},
+		Labels: map[string]string{"env": "prod"},
+		Values: []int{1, 2},
+		Nested: []*simpleTest{{2}},
+		Any:    &simpleTest{3},
+		hidden: 4,
+	}
+	var dst cloneTest
+	if err := Clone(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dst, src) {
+		t.Fatalf("Test failed:\ngot  :\n%+v\n\nwant :\n%+v\n\n", dst, src)
+	}
+	dst.Ptr.Value = 10
+	dst.Labels["env"] = "dev"
+	dst.Values[0] = 10
+	dst.Nested[0].Value = 10
+	dst.Any.(*simpleTest).Value = 10
+	if src.Ptr.Value != 1 || src.Labels["env"] != "prod" || src.Values[0] != 1 || src.Nested[0].Value != 2 || src.Any.(*simpleTest).Value != 3 {
+		t.Fatalf("changes to the clone leaked into src: %+v", src)
+	}
+}
+
+func TestCloneCircularPointerStruct(t *testing.T) {
+	src := list{&list{}}
+	src.Next.Next = &src
+	var dst list
+	if err := Clone(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Next == src.Next {
+		t.Fatalf("dst.Next should be a different pointer than src.Next")
+	}
+	if dst.Next.Next.Next != dst.Next {
+		t.Fatalf("cycle not preserved in clone")
+	}
+}
+
+func TestCloneDifferentTypes(t *testing.T) {
+	a := simpleTest{}
+	if err := Clone(&a, complexTest{}); err != ErrDifferentArgumentsTypes {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMergeWithDeepCopy(t *testing.T) {
+	src := cloneTest{
+		Ptr:    &simpleTest{1},
+		Labels: map[string]string{"env": "prod"},
+		Values: []int{1, 2},
+	}
+	dst := cloneTest{Values: []int{0}}
+	if err := Merge(&dst, src, WithDeepCopy); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Ptr == src.Ptr {
+		t.Fatalf("dst.Ptr should be a different pointer than src.Ptr")
+	}
+	dst.Labels["env"] = "dev"
+	dst.Values[1] = 10
+	if src.Labels["env"] != "prod" || src.Values[0] != 1 {
+		t.Fatalf("changes to dst leaked into src: %+v", src)
+	}
+	m := map[string]interface{}{"a": 1}
+	if err := Merge(&m, map[string]interface{}{"b": []int{1}}, WithDeepCopy); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, map[string]interface{}{"a": 1, "b": []int{1}}) {
+		t.Fatalf("unexpected map: %+v", m)
+	}
+}
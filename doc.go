@@ -6,7 +6,7 @@
 /*
 Package mergo merges same-type structs and maps by setting default values in zero-value fields.
 
-Mergo won't merge unexported (private) fields but will do recursively any exported one. It also won't merge structs inside maps (because they are not addressable using Go reflection).
+Mergo won't merge unexported (private) fields but will do recursively any exported one. Structs inside maps, which aren't addressable using Go reflection, are merged into a copy that is stored back in the map. When overriding, map values are replaced as a whole unless WithDeepMerge is used.
 
 Usage
 
@@ -0,0 +1,45 @@
+// Copyright 2013 Dario Castañé. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mergo
+
+import (
+	"reflect"
+)
+
+// Emptier is implemented by types telling by themselves whether their values
+// are empty, and so must be filled by merge, like time.Time. Only value
+// receivers are considered: pointers are empty when nil.
+type Emptier interface {
+	IsZero() bool
+}
+
+// WithEmptyFunc will make merge decide whether values of type t are empty using isEmpty,
+// instead of their IsZero method or the default rules, e.g. to treat a custom Optional
+// struct as empty when it isn't set.
+func WithEmptyFunc(t reflect.Type, isEmpty func(v reflect.Value) bool) func(*Config) {
+	return func(config *Config) {
+		if config.EmptyFuncs == nil {
+			config.EmptyFuncs = make(map[reflect.Type]func(reflect.Value) bool)
+		}
+		config.EmptyFuncs[t] = isEmpty
+	}
+}
+
+// isEmpty reports whether v is empty, as decided by config's function for
+// its type, or by its IsZero method, or else by isEmptyValue.
+func isEmpty(v reflect.Value, config *Config) bool {
+	if !v.IsValid() {
+		return isEmptyValue(v)
+	}
+	if fn := config.EmptyFuncs[v.Type()]; fn != nil {
+		return fn(v)
+	}
+	if k := v.Kind(); k != reflect.Ptr && k != reflect.Interface && v.CanInterface() {
+		if e, ok := v.Interface().(Emptier); ok {
+			return e.IsZero()
+		}
+	}
+	return isEmptyValue(v)
+}
@@ -0,0 +1,71 @@
+package mergo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type optionalInt struct {
+	Value int
+	Set   bool
+}
+
+func (o optionalInt) IsZero() bool {
+	return !o.Set
+}
+
+type emptierTest struct {
+	Created time.Time
+	Retries optionalInt
+	Port    struct{ Number int }
+}
+
+func TestMergeEmptier(t *testing.T) {
+	now := time.Now()
+	dst := emptierTest{Retries: optionalInt{2, true}}
+	src := emptierTest{Created: now, Retries: optionalInt{3, true}}
+	src.Port.Number = 80
+	if err := Merge(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if !dst.Created.Equal(now) {
+		t.Fatalf("zero time.Time not filled: %v", dst.Created)
+	}
+	if dst.Retries != (optionalInt{2, true}) {
+		t.Fatalf("set optional overwritten: %+v", dst.Retries)
+	}
+	if dst.Port.Number != 80 {
+		t.Fatalf("struct without IsZero not merged: %+v", dst.Port)
+	}
+	later := emptierTest{Retries: optionalInt{5, false}}
+	if err := Merge(&dst, later, WithOverride); err != nil {
+		t.Fatal(err)
+	}
+	if !dst.Created.Equal(now) || dst.Retries != (optionalInt{2, true}) {
+		t.Fatalf("empty values overwrote dst: %+v", dst)
+	}
+}
+
+func TestMergeWithEmptyFunc(t *testing.T) {
+	portType := reflect.TypeOf(struct{ Number int }{})
+	noPort := func(v reflect.Value) bool {
+		return v.Field(0).Int() <= 0
+	}
+	dst := emptierTest{}
+	dst.Port.Number = -1
+	src := emptierTest{}
+	src.Port.Number = 80
+	if err := Merge(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Port.Number != -1 {
+		t.Fatalf("port overwritten without WithEmptyFunc: %+v", dst.Port)
+	}
+	if err := Merge(&dst, src, WithEmptyFunc(portType, noPort)); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Port.Number != 80 {
+		t.Fatalf("port considered empty not filled: %+v", dst.Port)
+	}
+}
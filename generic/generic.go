@@ -0,0 +1,56 @@
+// Copyright 2013 Dario Castañé. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+/*
+Package generic provides a type-safe API on top of mergo using type parameters.
+
+The compiler checks that dst is a pointer to the type of src, mistakes that
+mergo's interface{} API only reports at runtime:
+
+	var config Config
+	if err := generic.Merge(&config, defaults, mergo.WithOverride); err != nil {
+		log.Fatal(err)
+	}
+
+T must be a struct or map type, as mergo requires.
+*/
+package generic
+
+import (
+	"github.com/imdario/mergo"
+)
+
+// Merge merges src into dst as mergo.Merge does.
+func Merge[T any](dst *T, src T, opts ...func(*mergo.Config)) error {
+	if dst == nil {
+		return mergo.ErrNilArguments
+	}
+	return mergo.Merge(dst, src, opts...)
+}
+
+// MergePtr merges the value src points to into dst as mergo.Merge does.
+func MergePtr[T any](dst, src *T, opts ...func(*mergo.Config)) error {
+	if dst == nil || src == nil {
+		return mergo.ErrNilArguments
+	}
+	return mergo.Merge(dst, src, opts...)
+}
+
+// Merged returns a new value with the result of merging b into a, as
+// mergo.Merge does, leaving both untouched. The result doesn't share
+// pointers, maps or slices with a or b.
+func Merged[T any](a, b T, opts ...func(*mergo.Config)) (T, error) {
+	var r T
+	if err := mergo.Clone(&r, a); err != nil {
+		return r, err
+	}
+	// opts is copied, so the caller's slice is never appended to.
+	if err := mergo.Merge(&r, b, append(opts[:len(opts):len(opts)], mergo.WithDeepCopy)...); err != nil {
+		return r, err
+	}
+	return r, nil
+}
@@ -0,0 +1,64 @@
+//go:build go1.18
+// +build go1.18
+
+package generic
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/imdario/mergo"
+)
+
+type server struct {
+	Name   string
+	Port   int
+	Labels map[string]string
+}
+
+func TestMerge(t *testing.T) {
+	dst := server{Name: "api"}
+	src := server{Name: "web", Port: 80}
+	if err := Merge(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (server{Name: "api", Port: 80}); !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("got %+v expected %+v", dst, expected)
+	}
+	if err := MergePtr(&dst, &src, mergo.WithOverride); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dst, src) {
+		t.Fatalf("got %+v expected %+v", dst, src)
+	}
+	if err := Merge(nil, src); err != mergo.ErrNilArguments {
+		t.Fatalf("expected ErrNilArguments, got %v", err)
+	}
+}
+
+func TestMerged(t *testing.T) {
+	a := server{Name: "api", Labels: map[string]string{"env": "prod"}}
+	b := server{Port: 80, Labels: map[string]string{"tier": "backend"}}
+	r, err := Merged(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := server{Name: "api", Port: 80, Labels: map[string]string{"env": "prod", "tier": "backend"}}
+	if !reflect.DeepEqual(r, expected) {
+		t.Fatalf("got %+v expected %+v", r, expected)
+	}
+	if len(a.Labels) != 1 || a.Port != 0 {
+		t.Fatalf("a was modified: %+v", a)
+	}
+}
+
+func TestMergedLeavesOptsUntouched(t *testing.T) {
+	opts := make([]func(*mergo.Config), 1, 2)
+	opts[0] = mergo.WithOverride
+	if _, err := Merged(server{Name: "api"}, server{Port: 80}, opts...); err != nil {
+		t.Fatal(err)
+	}
+	if opts[:2][1] != nil {
+		t.Fatalf("Merged appended to the caller's opts")
+	}
+}
@@ -9,9 +9,9 @@
 package mergo
 
 import (
-	"fmt"
 	"reflect"
-	"unicode"
+	"sort"
+	"strings"
 	"unicode/utf8"
 )
 
@@ -31,30 +31,81 @@ func isExported(field reflect.StructField) bool {
 // Traverses recursively both values, assigning src's fields values to dst.
 // The map argument tracks comparisons that have already been seen, which allows
 // short circuiting on recursive types.
-func deepMap(dst, src reflect.Value, visited map[visit]bool, depth int, overwrite bool) error {
+func deepMap(dst, src reflect.Value, visited map[visit]bool, depth int, path string, config *Config) error {
+	overwrite := config.Overwrite
 	switch dst.Kind() {
 	case reflect.Map:
-		dstMap := dst.Interface().(map[string]interface{})
-		for i, n := 0, src.NumField(); i < n; i++ {
-			srcType := src.Type()
-			field := srcType.Field(i)
-			if !isExported(field) {
+		if dst.Type().Key().Kind() != reflect.String {
+			return ErrExpectedMapAsDestination
+		}
+		if dst.IsNil() {
+			if !dst.CanSet() {
+				return ErrNilArguments
+			}
+			dst.Set(reflect.MakeMap(dst.Type()))
+		}
+		keyType, elemType := dst.Type().Key(), dst.Type().Elem()
+		for _, field := range mapFields(src.Type(), config) {
+			fieldName, omitEmpty, _ := mapKey(field, config)
+			key := reflect.ValueOf(fieldName).Convert(keyType)
+			elementPath := keyPath(path, key)
+			srcElement := fieldByIndex(src, field.Index, false)
+			if !srcElement.IsValid() {
+				// It is promoted from a nil embedded pointer.
 				continue
 			}
-			fieldName := field.Name
-			fieldName = changeInitialCase(fieldName, unicode.ToLower)
-			if v, ok := dstMap[fieldName]; !ok || (isEmptyValue(reflect.ValueOf(v)) || overwrite) {
-				dstMap[fieldName] = src.Field(i).Interface()
+			if omitEmpty && isEmptyValue(srcElement) {
+				continue
+			}
+			dstElement := dst.MapIndex(key)
+			if config.NestedMaps {
+				if dstElement.IsValid() {
+					if m, ok := dstElement.Interface().(map[string]interface{}); ok && m != nil {
+						if s := indirectStruct(srcElement); s.IsValid() {
+							if err := deepMap(reflect.ValueOf(m), s, visited, depth+1, elementPath, config); err != nil {
+								return err
+							}
+							continue
+						}
+					}
+				}
+				var err error
+				if srcElement, err = nestedMapValue(srcElement, visited, depth, elementPath, config); err != nil {
+					return err
+				}
+			}
+			srcElement, err := mapElement(srcElement, elemType, config)
+			if err != nil {
+				return newMergeError(elementPath, reflect.Zero(elemType), srcElement, err)
+			}
+			if !dstElement.IsValid() || isEmpty(reflect.ValueOf(dstElement.Interface()), config) {
+				setMapIndex(dst, key, copyOf(srcElement, config), elementPath, ReasonFill, config)
+			} else if config.Strict {
+				if v := dstElement.Interface(); !isEmpty(srcElement, config) && !reflect.DeepEqual(v, srcElement.Interface()) {
+					*config.conflicts = append(*config.conflicts, Conflict{elementPath, v, srcElement.Interface()})
+				}
+			} else if overwrite {
+				setMapIndex(dst, key, copyOf(srcElement, config), elementPath, ReasonOverwrite, config)
 			}
 		}
 	case reflect.Struct:
 		srcMap := src.Interface().(map[string]interface{})
 		for key := range srcMap {
 			srcValue := srcMap[key]
-			fieldName := changeInitialCase(key, unicode.ToUpper)
-			dstElement := dst.FieldByName(fieldName)
-			if !dstElement.IsValid() {
+			field, ok := fieldByKey(dst.Type(), key, config)
+			if !ok {
 				// We discard it because the field doesn't exist.
+				if config.unknownKeys != nil {
+					*config.unknownKeys = append(*config.unknownKeys, fieldPath(config.keyPath, key))
+				}
+				continue
+			}
+			c, skip := fieldConfig(field, config)
+			if skip {
+				continue
+			}
+			dstElement := fieldByIndex(dst, field.Index, true)
+			if !dstElement.IsValid() {
 				continue
 			}
 			srcElement := reflect.ValueOf(srcValue)
@@ -74,17 +125,36 @@ func deepMap(dst, src reflect.Value, visited map[visit]bool, depth int, overwrit
 			if !srcElement.IsValid() {
 				continue
 			}
+			if config.WeakTypes && srcElement.Type() != dstElement.Type() && isWeakKind(dstElement.Type()) {
+				converted, err := weakConvert(srcElement, dstElement.Type())
+				if err != nil {
+					return newMergeError(fieldPath(path, field.Name), dstElement, srcElement, err)
+				}
+				srcElement, srcKind = converted, converted.Kind()
+			}
 			if srcKind == dstKind {
-				if err := deepMerge(dstElement, srcElement, visited, depth+1, overwrite); err != nil {
+				if err := deepMerge(dstElement, srcElement, visited, depth+1, fieldPath(path, field.Name), c); err != nil {
 					return err
 				}
 			} else {
 				if srcKind == reflect.Map {
-					if err := deepMap(dstElement, srcElement, visited, depth+1, overwrite); err != nil {
+					if dstKind == reflect.Ptr && dstElement.Type().Elem().Kind() == reflect.Struct {
+						if dstElement.IsNil() {
+							dstElement.Set(reflect.New(dstElement.Type().Elem()))
+						}
+						dstElement = dstElement.Elem()
+					}
+					if dstElement.Kind() != reflect.Struct {
+						return newMergeError(fieldPath(path, field.Name), dstElement, srcElement, ErrDifferentArgumentsTypes)
+					}
+					// Unknown keys are reported with the keys leading to them.
+					kc := *c
+					kc.keyPath = fieldPath(config.keyPath, key)
+					if err := deepMap(dstElement, srcElement, visited, depth+1, fieldPath(path, field.Name), &kc); err != nil {
 						return err
 					}
 				} else {
-					return fmt.Errorf("type mismatch on %s field: found %v, expected %v", fieldName, srcKind, dstKind)
+					return newMergeError(fieldPath(path, field.Name), dstElement, srcElement, ErrDifferentArgumentsTypes)
 				}
 			}
 		}
@@ -92,39 +162,207 @@ func deepMap(dst, src reflect.Value, visited map[visit]bool, depth int, overwrit
 	return nil
 }
 
+// fieldByIndex returns the nested field of struct v with the given index, as
+// reflect.Value.FieldByIndex does. Nil pointers to embedded structs along the
+// way are allocated if alloc is set and they can be, and otherwise an invalid
+// value is returned.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// mapElement returns v as a value of the element type t of the map a struct
+// is being mapped to: as it is if assignable, converted if it is of the same
+// kind, like a named string type into string, or, using WithWeakTypes,
+// converted as weakConvert does.
+func mapElement(v reflect.Value, t reflect.Type, config *Config) (reflect.Value, error) {
+	switch {
+	case v.Type().AssignableTo(t):
+		return v, nil
+	case config.WeakTypes && isWeakKind(t):
+		return weakConvert(v, t)
+	case v.Kind() == t.Kind() && v.Type().ConvertibleTo(t):
+		return v.Convert(t), nil
+	}
+	return v, ErrInvalidConversion
+}
+
+// indirectStruct returns the struct v is or points to, if it has exported
+// fields to map, or an invalid value otherwise.
+func indirectStruct(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	for i, n := 0, v.NumField(); i < n; i++ {
+		if isExported(v.Type().Field(i)) {
+			return v
+		}
+	}
+	return reflect.Value{}
+}
+
+// Returns v converted to a tree of map[string]interface{}: structs, and
+// pointers to them, become maps, and slices and maps holding them become
+// []interface{} and map[string]interface{}. Structs without exported
+// fields, like time.Time, and pointers already being converted, which
+// would make a cycle, are kept as they are.
+func nestedMapValue(v reflect.Value, visited map[visit]bool, depth int, path string, config *Config) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+		return nestedMapValue(v.Elem(), visited, depth, path, config)
+	case reflect.Ptr, reflect.Struct:
+		s := indirectStruct(v)
+		if !s.IsValid() {
+			return v, nil
+		}
+		if v.Kind() == reflect.Ptr {
+			key := visit{v.Pointer(), 0, v.Type()}
+			if visited[key] {
+				return v, nil
+			}
+			visited[key] = true
+			defer delete(visited, key)
+		}
+		// A fresh map is filled, so there is nothing to report.
+		c := *config
+		c.changes = nil
+		m := make(map[string]interface{})
+		if err := deepMap(reflect.ValueOf(m), s, visited, depth+1, path, &c); err != nil {
+			return v, err
+		}
+		return reflect.ValueOf(m), nil
+	case reflect.Slice, reflect.Array:
+		if !hasNestedMaps(v.Type().Elem()) || (v.Kind() == reflect.Slice && v.IsNil()) {
+			return v, nil
+		}
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			e, err := nestedMapValue(v.Index(i), visited, depth+1, indexPath(path, i), config)
+			if err != nil {
+				return v, err
+			}
+			s[i] = e.Interface()
+		}
+		return reflect.ValueOf(s), nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String || !hasNestedMaps(v.Type().Elem()) || v.IsNil() {
+			return v, nil
+		}
+		m := make(map[string]interface{}, v.Len())
+		for _, key := range v.MapKeys() {
+			e, err := nestedMapValue(v.MapIndex(key), visited, depth+1, keyPath(path, key), config)
+			if err != nil {
+				return v, err
+			}
+			m[key.String()] = e.Interface()
+		}
+		return reflect.ValueOf(m), nil
+	}
+	return v, nil
+}
+
+// hasNestedMaps reports whether values of type t may be converted by
+// nestedMapValue.
+func hasNestedMaps(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
+
 // Map sets fields' values in dst from src.
 // src can be a map with string keys or a struct. dst must be the opposite:
 // if src is a map, dst must be a valid pointer to struct. If src is a struct,
-// dst must be map[string]interface{}.
+// dst must be a map with string keys, like map[string]interface{} or
+// map[string]string, whose values fields' values are converted to, failing
+// with ErrInvalidConversion if they can't be. A nil dst map is made.
 // It won't merge unexported (private) fields and will do recursively
 // any exported field.
-// If dst is a map, keys will be src fields' names in lower camel case.
-// Missing key in src that doesn't match a field in dst will be skipped. This
-// doesn't apply if dst is a map.
+// If dst is a map, keys will be src fields' names in lower camel case, unless
+// set otherwise with WithKeyNamer or WithTagName, and,
+// unless WithNestedMaps is used, values will be src fields' values as they are.
+// Missing key in src that doesn't match a field in dst will be skipped, unless
+// WithErrorOnUnknownKeys is used. This doesn't apply if dst is a map.
 // This is separated method from Merge because it is cleaner and it keeps sane
 // semantics: merging equal types, mapping different (restricted) types.
-func Map(dst, src interface{}) error {
-	return _map(dst, src, false)
+// It accepts the same options as Merge.
+func Map(dst, src interface{}, opts ...func(*Config)) error {
+	return _map(dst, src, opts...)
 }
 
 // MapWithOverwrite will do the same as Map except that non-empty dst attributes will be overriden by
 // non-empty src attribute values.
-func MapWithOverwrite(dst, src interface{}) error {
-	return _map(dst, src, true)
+//
+// Deprecated: use Map(dst, src, WithOverride) instead.
+func MapWithOverwrite(dst, src interface{}, opts ...func(*Config)) error {
+	return _map(dst, src, append(opts, WithOverride)...)
+}
+
+// WithNestedMaps will make Map, when mapping a struct to a map, convert nested structs to
+// map[string]interface{} too, and slices and maps holding structs to []interface{} and
+// map[string]interface{}, so dst is a tree of generic values. Structs without exported fields,
+// like time.Time, are kept as they are.
+func WithNestedMaps(config *Config) {
+	config.NestedMaps = true
+}
+
+// WithSquash will make Map map the fields of embedded structs, and pointers to them, as
+// fields of the embedding struct, as if they were promoted to it, in both directions. It
+// can be set for a single embedded struct with the squash option of the mergo tag, or of
+// the tag named with WithTagName, e.g. `json:",squash"`. Embedded structs with a key set in
+// their tags aren't squashed.
+func WithSquash(config *Config) {
+	config.Squash = true
+}
+
+// WithTagName will make Map take keys from the struct tag named tagName, like json or yaml,
+// following encoding/json conventions: a key of "-" leaves the field out and the omitempty
+// option leaves it out of maps when empty. Keys can also be set with the mergo tag, as in
+// `mergo:"name=server_type"`.
+func WithTagName(tagName string) func(*Config) {
+	return func(config *Config) {
+		config.TagName = tagName
+	}
 }
 
-func _map(dst, src interface{}, overwrite bool) error {
+func _map(dst, src interface{}, opts ...func(*Config)) error {
 	var (
 		vDst, vSrc reflect.Value
 		err        error
 	)
+	config := newConfig(opts)
 	if vDst, vSrc, err = resolveValues(dst, src); err != nil {
 		return err
 	}
+	vDst = dryRunTarget(vDst, config)
 	// To be friction-less, we redirect equal-type arguments
 	// to deepMerge. Only because arguments can be anything.
 	if vSrc.Kind() == vDst.Kind() {
-		return deepMerge(vDst, vSrc, make(map[visit]bool), 0, overwrite)
+		if err = deepMerge(vDst, vSrc, make(map[visit]bool), 0, "", config); err != nil {
+			return err
+		}
+		return conflictError(config)
 	}
 	switch vSrc.Kind() {
 	case reflect.Struct:
@@ -138,5 +376,39 @@ func _map(dst, src interface{}, overwrite bool) error {
 	default:
 		return ErrNotSupported
 	}
-	return deepMap(vDst, vSrc, make(map[visit]bool), 0, overwrite)
+	if err = deepMap(vDst, vSrc, make(map[visit]bool), 0, "", config); err != nil {
+		return err
+	}
+	if err = unknownKeysError(config); err != nil {
+		return err
+	}
+	return conflictError(config)
+}
+
+// UnknownKeysError is returned by Map when using WithErrorOnUnknownKeys if
+// src has keys not mapped to any field of dst.
+type UnknownKeysError struct {
+	// Keys are the paths of the unknown keys, made of the keys of src's
+	// nested maps leading to them, e.g. network.timout.
+	Keys []string
+}
+
+func (e *UnknownKeysError) Error() string {
+	return "mergo: unknown keys " + strings.Join(e.Keys, ", ")
+}
+
+// WithErrorOnUnknownKeys will make Map, when mapping a map to a struct, fail with an
+// *UnknownKeysError listing every key of src, nested ones included, not mapped to any field.
+func WithErrorOnUnknownKeys(config *Config) {
+	config.ErrorOnUnknownKeys = true
+}
+
+// unknownKeysError returns the unknown keys found while mapping with config, if any.
+func unknownKeysError(config *Config) error {
+	if config.unknownKeys == nil || len(*config.unknownKeys) == 0 {
+		return nil
+	}
+	keys := *config.unknownKeys
+	sort.Strings(keys)
+	return &UnknownKeysError{keys}
 }
@@ -0,0 +1,323 @@
+package mergo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type nestedMapTest struct {
+	Name    string
+	Network networkConfig
+	Backup  *networkConfig
+	Servers []networkConfig
+	Ports   []int
+	Created time.Time
+}
+
+func TestMapWithNestedMaps(t *testing.T) {
+	now := time.Now()
+	src := nestedMapTest{
+		Name:    "foo",
+		Network: networkConfig{Protocol: "tcp", Port: 80},
+		Backup:  &networkConfig{Protocol: "udp"},
+		Servers: []networkConfig{{Port: 1}, {Port: 2}},
+		Ports:   []int{1, 2},
+		Created: now,
+	}
+	dst := map[string]interface{}{
+		"network": map[string]interface{}{"protocol": "http"},
+	}
+	if err := Map(&dst, src, WithNestedMaps); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"name":    "foo",
+		"network": map[string]interface{}{"protocol": "http", "port": 80},
+		"backup":  map[string]interface{}{"protocol": "udp", "port": 0},
+		"servers": []interface{}{
+			map[string]interface{}{"protocol": "", "port": 1},
+			map[string]interface{}{"protocol": "", "port": 2},
+		},
+		"ports":   []int{1, 2},
+		"created": now,
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Test failed:\ngot  :\n%+v\n\nwant :\n%+v\n\n", dst, expected)
+	}
+}
+
+func TestMapWithNestedMapsCycle(t *testing.T) {
+	src := list{&list{}}
+	src.Next.Next = &src
+	dst := make(map[string]interface{})
+	if err := Map(&dst, src, WithNestedMaps); err != nil {
+		t.Fatal(err)
+	}
+	next, ok := dst["next"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("next not converted to a map: %+v", dst)
+	}
+	// src is mapped by value, so the cycle is closed on src.Next.
+	if next, ok = next["next"].(map[string]interface{}); !ok {
+		t.Fatalf("next.next not converted to a map: %+v", dst)
+	}
+	if next["next"] != src.Next {
+		t.Fatalf("cyclic pointer should have been kept as is: %+v", next)
+	}
+}
+
+type taggedMapTest struct {
+	ServerType string `json:"server_type"`
+	Address    string `json:"address,omitempty"`
+	Secret     string `json:"-"`
+	Port       int    `mergo:"name=listen_port"`
+	Protocol   string
+}
+
+func TestMapWithTagName(t *testing.T) {
+	src := taggedMapTest{ServerType: "http", Secret: "foo", Port: 80, Protocol: "tcp"}
+	m := make(map[string]interface{})
+	if err := Map(&m, src, WithTagName("json")); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"server_type": "http",
+		"listen_port": 80,
+		"protocol":    "tcp",
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Fatalf("Test failed:\ngot  :\n%+v\n\nwant :\n%+v\n\n", m, expected)
+	}
+	m["address"] = "127.0.0.1"
+	m["secret"] = "bar"
+	var dst taggedMapTest
+	if err := Map(&dst, m, WithTagName("json")); err != nil {
+		t.Fatal(err)
+	}
+	expectedDst := taggedMapTest{ServerType: "http", Address: "127.0.0.1", Port: 80, Protocol: "tcp"}
+	if !reflect.DeepEqual(dst, expectedDst) {
+		t.Fatalf("Test failed:\ngot  :\n%+v\n\nwant :\n%+v\n\n", dst, expectedDst)
+	}
+}
+
+func TestMapWithDashKey(t *testing.T) {
+	src := struct {
+		Dash   string `json:"-,"`
+		Hidden string `json:"-"`
+	}{"foo", "bar"}
+	m := make(map[string]interface{})
+	if err := Map(&m, src, WithTagName("json")); err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]interface{}{"-": "foo"}; !reflect.DeepEqual(m, expected) {
+		t.Fatalf("got %v expected %v", m, expected)
+	}
+}
+
+func TestMapPromotedFieldsWithTagName(t *testing.T) {
+	type Inner struct {
+		Secret string `json:"-"`
+		Host   string `json:"address"`
+		Port   int
+	}
+	type outer struct {
+		*Inner
+	}
+	src := map[string]interface{}{"secret": "x", "host": "a", "port": 80}
+	dst := outer{&Inner{}}
+	if err := Map(&dst, src, WithTagName("json")); err != nil {
+		t.Fatal(err)
+	}
+	if *dst.Inner != (Inner{Port: 80}) {
+		t.Fatalf("promoted fields left out or with a key matched by name: %+v", *dst.Inner)
+	}
+}
+
+func TestMapWithMergoTagName(t *testing.T) {
+	src := struct {
+		Hosts []string `mergo:"replace"`
+		Port  int      `mergo:"replace,name=listen_port"`
+	}{[]string{"a"}, 80}
+	m := make(map[string]interface{})
+	if err := Map(&m, src, WithTagName("mergo")); err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]interface{}{"hosts": []string{"a"}, "listen_port": 80}; !reflect.DeepEqual(m, expected) {
+		t.Fatalf("got %v expected %v", m, expected)
+	}
+}
+
+func TestMapWithoutTagName(t *testing.T) {
+	src := taggedMapTest{ServerType: "http", Port: 80}
+	m := make(map[string]interface{})
+	if err := Map(&m, src); err != nil {
+		t.Fatal(err)
+	}
+	if m["serverType"] != "http" || m["listen_port"] != 80 {
+		t.Fatalf("unexpected keys: %+v", m)
+	}
+}
+
+func TestMapWithErrorOnUnknownKeys(t *testing.T) {
+	src := map[string]interface{}{
+		"name":   "foo",
+		"timout": 30,
+		"network": map[string]interface{}{
+			"protocol": "tcp",
+			"prot":     80,
+		},
+	}
+	var dst nestedMapTest
+	if err := Map(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	dst = nestedMapTest{}
+	err := Map(&dst, src, WithErrorOnUnknownKeys)
+	unknownErr, ok := err.(*UnknownKeysError)
+	if !ok {
+		t.Fatalf("expected an *UnknownKeysError, got %v", err)
+	}
+	if expected := []string{"network.prot", "timout"}; !reflect.DeepEqual(unknownErr.Keys, expected) {
+		t.Fatalf("got %v expected %v", unknownErr.Keys, expected)
+	}
+	if dst.Name != "foo" || dst.Network.Protocol != "tcp" {
+		t.Fatalf("known keys not mapped: %+v", dst)
+	}
+}
+
+func TestMapWithErrorOnUnknownKeysTaggedAndPointers(t *testing.T) {
+	type unknownKeysTest struct {
+		Net    networkConfig  `json:"net"`
+		Backup *networkConfig `json:"backup"`
+	}
+	src := map[string]interface{}{
+		"net":    map[string]interface{}{"prot": 80},
+		"backup": map[string]interface{}{"protocol": "udp", "bogus": 1},
+	}
+	var dst unknownKeysTest
+	err := Map(&dst, src, WithTagName("json"), WithErrorOnUnknownKeys)
+	unknownErr, ok := err.(*UnknownKeysError)
+	if !ok {
+		t.Fatalf("expected an *UnknownKeysError, got %v", err)
+	}
+	if expected := []string{"backup.bogus", "net.prot"}; !reflect.DeepEqual(unknownErr.Keys, expected) {
+		t.Fatalf("got %v expected %v", unknownErr.Keys, expected)
+	}
+	if dst.Backup == nil || dst.Backup.Protocol != "udp" {
+		t.Fatalf("map not mapped into pointer field: %+v", dst.Backup)
+	}
+}
+
+type labelName string
+
+type labelValue string
+
+type typedMapTest struct {
+	Name  string
+	Owner labelValue
+	Tier  string
+}
+
+func TestMapToTypedMaps(t *testing.T) {
+	src := typedMapTest{Name: "api", Owner: "infra"}
+	var values map[string]string
+	if err := Map(&values, src); err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]string{"name": "api", "owner": "infra", "tier": ""}; !reflect.DeepEqual(values, expected) {
+		t.Fatalf("got %v expected %v", values, expected)
+	}
+	labels := map[labelName]labelValue{"tier": "backend", "owner": "web"}
+	if err := Map(&labels, src); err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[labelName]labelValue{"name": "api", "owner": "web", "tier": "backend"}; !reflect.DeepEqual(labels, expected) {
+		t.Fatalf("got %v expected %v", labels, expected)
+	}
+}
+
+func TestMapToTypedMapInvalidConversion(t *testing.T) {
+	src := struct {
+		Name string
+		Port int
+	}{"api", 8080}
+	var dst map[string]string
+	err := Map(&dst, src)
+	if !errors.Is(err, ErrInvalidConversion) {
+		t.Fatalf("expected ErrInvalidConversion, got %v", err)
+	}
+	if mergeErr, ok := err.(*MergeError); !ok || mergeErr.Path != `["port"]` {
+		t.Fatalf("expected a MergeError at [\"port\"], got %v", err)
+	}
+	dst = nil
+	if err := Map(&dst, src, WithWeakTypes); err != nil {
+		t.Fatal(err)
+	}
+	if dst["port"] != "8080" {
+		t.Fatalf("expected port to be converted, got %v", dst)
+	}
+}
+
+type BaseConfig struct {
+	Name    string
+	Timeout int
+}
+
+type LimitsConfig struct {
+	Retries int
+}
+
+type squashMapTest struct {
+	BaseConfig    `mergo:"squash"`
+	*LimitsConfig `json:",squash"`
+	Name          string
+}
+
+func TestMapSquash(t *testing.T) {
+	src := squashMapTest{
+		BaseConfig:   BaseConfig{Name: "base", Timeout: 30},
+		LimitsConfig: &LimitsConfig{Retries: 3},
+		Name:         "api",
+	}
+	m := make(map[string]interface{})
+	if err := Map(&m, src, WithTagName("json")); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{"name": "api", "timeout": 30, "retries": 3}
+	if !reflect.DeepEqual(m, expected) {
+		t.Fatalf("got %v expected %v", m, expected)
+	}
+	var dst squashMapTest
+	if err := Map(&dst, m, WithTagName("json")); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Name != "api" || dst.BaseConfig.Name != "" || dst.Timeout != 30 || dst.LimitsConfig == nil || dst.Retries != 3 {
+		t.Fatalf("embedded structs not filled back: %+v %+v", dst, dst.LimitsConfig)
+	}
+}
+
+func TestMapWithSquash(t *testing.T) {
+	type embeddingMapTest struct {
+		BaseConfig
+		*LimitsConfig
+		Port int
+	}
+	src := embeddingMapTest{BaseConfig: BaseConfig{Name: "api"}, Port: 80}
+	m := make(map[string]interface{})
+	if err := Map(&m, src); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m["baseConfig"]; !ok {
+		t.Fatalf("embedded struct expected to be a single key without WithSquash: %v", m)
+	}
+	m = make(map[string]interface{})
+	if err := Map(&m, src, WithSquash); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{"name": "api", "timeout": 0, "port": 80}
+	if !reflect.DeepEqual(m, expected) {
+		t.Fatalf("got %v expected %v", m, expected)
+	}
+}
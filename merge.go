@@ -9,7 +9,6 @@
 package mergo
 
 import (
-	"fmt"
 	"reflect"
 )
 
@@ -18,10 +17,17 @@ var indent = 0
 // Traverses recursively both values, assigning src's fields values to dst.
 // The map argument tracks comparisons that have already been seen, which allows
 // short circuiting on recursive types.
-func deepMerge(dst, src reflect.Value, visited map[visit]bool, depth int, overwrite bool) error {
+func deepMerge(dst, src reflect.Value, visited map[visit]bool, depth int, path string, config *Config) error {
+	overwrite := config.Overwrite
+
 	mergeStructs := func(dst, src reflect.Value) error {
 		for i, n := 0, dst.NumField(); i < n; i++ {
-			if err := deepMerge(dst.Field(i), src.Field(i), visited, depth+1, overwrite); err != nil {
+			field := dst.Type().Field(i)
+			c, skip := fieldConfig(field, config)
+			if skip {
+				continue
+			}
+			if err := deepMerge(dst.Field(i), src.Field(i), visited, depth+1, fieldPath(path, field.Name), c); err != nil {
 				return err
 			}
 		}
@@ -33,22 +39,46 @@ func deepMerge(dst, src reflect.Value, visited map[visit]bool, depth int, overwr
 		for _, key := range src.MapKeys() {
 			srcElement := src.MapIndex(key)
 			dstElement := dst.MapIndex(key)
-			if !dstElement.IsValid() || isEmptyValue(dstElement) || overwrite {
-				dst.SetMapIndex(key, srcElement)
+			elementPath := keyPath(path, key)
+			if !dstElement.IsValid() || isEmpty(dstElement, config) {
+				if !hasTransformer(srcElement, config) {
+					setMapIndex(dst, key, copyOf(srcElement, config), elementPath, ReasonFill, config)
+					continue
+				}
+				if !dstElement.IsValid() || !reflect.ValueOf(dstElement.Interface()).IsValid() {
+					// The transformer gets a zero value to merge into.
+					dstElement = reflect.Zero(reflect.ValueOf(srcElement.Interface()).Type())
+				}
+			} else if overwrite && !config.deep && !mergesSlices(dstElement, srcElement, config) && !isMerger(reflect.ValueOf(dstElement.Interface()).Type()) && !hasTransformer(dstElement, config) {
+				setMapIndex(dst, key, copyOf(srcElement, config), elementPath, ReasonOverwrite, config)
 				continue
 			}
 			// if srcElement is an unexported field, give up. We can't get the value.
 			if !srcElement.CanInterface() {
 				continue
 			}
-			srcElement = reflect.ValueOf(srcElement.Interface())
-			dstElement = reflect.ValueOf(dstElement.Interface())
-			// make a settable value to merge into
+			srcValue, dstValue := reflect.ValueOf(srcElement.Interface()), reflect.ValueOf(dstElement.Interface())
+			if !srcValue.IsValid() || srcValue.Type() != dstValue.Type() {
+				// Values of different types, or nil, in interface maps can't
+				// be merged recursively: they are leaves.
+				if !srcValue.IsValid() && !config.OverwriteWithEmptyValue {
+					continue
+				}
+				d := reflect.New(dst.Type().Elem()).Elem()
+				d.Set(dstElement)
+				if err := resolve(d, srcElement, elementPath, overwrite, config); err != nil {
+					return err
+				}
+				dst.SetMapIndex(key, d)
+				continue
+			}
+			srcElement, dstElement = srcValue, dstValue
+			// Map values, like structs, aren't addressable: they are merged
+			// into a settable copy which is then stored back.
 			d := reflect.New(dstElement.Type()).Elem()
 			d.Set(dstElement)
-			err := deepMerge(d, srcElement, visited, depth+1, overwrite)
-			if err != nil {
-				continue
+			if err := deepMerge(d, srcElement, visited, depth+1, elementPath, config); err != nil {
+				return err
 			}
 			dst.SetMapIndex(key, d)
 		}
@@ -56,7 +86,10 @@ func deepMerge(dst, src reflect.Value, visited map[visit]bool, depth int, overwr
 	}
 
 	if src.Type() != dst.Type() {
-		return fmt.Errorf("src and dst must be same type (%s) != (%s)", src.Type().String(), dst.Type().String())
+		if compatibleTypes(dst.Type(), src.Type(), config) {
+			return mergeCompatible(dst, src, visited, depth, path, config)
+		}
+		return newMergeError(path, dst, src, ErrDifferentArgumentsTypes)
 	}
 
 	// Taken from reflect.DeepEqual
@@ -88,62 +121,365 @@ func deepMerge(dst, src reflect.Value, visited map[visit]bool, depth int, overwr
 		visited[v] = true
 	}
 
-	if !src.IsValid() || !dst.IsValid() || isEmptyValue(src) {
+	if !src.IsValid() || !dst.IsValid() {
 		return nil
 	}
 
-	if isEmptyValue(dst) {
+	if config.Transformers != nil && dst.CanSet() {
+		if fn := config.Transformers.Transformer(dst.Type()); fn != nil {
+			return transform(fn, dst, src, path, config)
+		}
+	}
+
+	if isEmpty(src, config) {
+		if config.OverwriteWithEmptyValue && !isEmpty(dst, config) && dst.CanSet() {
+			return resolve(dst, src, path, true, config)
+		}
+		return nil
+	}
+
+	if isEmpty(dst, config) {
+		if dst.CanSet() {
+			set(dst, copyOf(src, config), path, ReasonFill, config)
+		}
+		return nil
+	}
+
+	if config.replace {
 		if dst.CanSet() {
-			dst.Set(src)
+			set(dst, copyOf(src, config), path, ReasonOverwrite, config)
 		}
 		return nil
 	}
 
+	if m, ok := merger(dst); ok {
+		return mergeFrom(m, dst, src, path, config)
+	}
+
 	switch dst.Kind() {
 	case reflect.Struct:
 		return mergeStructs(dst, src)
 	case reflect.Map:
 		return mergeMaps(dst, src)
 	case reflect.Ptr, reflect.Interface:
-		if !overwrite && !isEmptyValue(dst) {
-			return deepMerge(dst.Elem(), src.Elem(), visited, depth+1, overwrite)
+		if dst.Kind() == reflect.Ptr && config.OverwriteExplicitZeros && dst.CanSet() && isEmpty(src.Elem(), config) {
+			// src points to an explicit zero value: dst's pointer is replaced,
+			// leaving the value it points to, maybe shared, untouched.
+			return resolve(dst, src, path, true, config)
+		}
+		if (!overwrite || config.deep || dst.Kind() == reflect.Ptr && isMerger(dst.Type().Elem())) && !isEmpty(dst, config) {
+			return deepMerge(dst.Elem(), src.Elem(), visited, depth+1, path, config)
 		}
 	case reflect.Slice:
-		if dst.CanSet() && !overwrite && !isEmptyValue(dst) {
-			dst.Set(reflect.AppendSlice(dst, src))
-			return nil
+		if dst.CanSet() {
+			return mergeSlices(dst, src, visited, depth, path, config)
 		}
 	}
-	if dst.CanSet() && overwrite {
-		dst.Set(src)
+	if dst.CanInterface() {
+		return resolve(dst, src, path, overwrite, config)
 	}
 	return nil
 }
 
+// hasTransformer reports whether config has a transformer for the type of
+// the value v, a map element, holds.
+func hasTransformer(v reflect.Value, config *Config) bool {
+	if config.Transformers == nil || !v.CanInterface() {
+		return false
+	}
+	if v = reflect.ValueOf(v.Interface()); !v.IsValid() {
+		return false
+	}
+	return config.Transformers.Transformer(v.Type()) != nil
+}
+
+// transform merges src into dst using fn, recording the change if any.
+func transform(fn func(dst, src reflect.Value) error, dst, src reflect.Value, path string, config *Config) error {
+	if config.changes == nil {
+		if err := fn(dst, src); err != nil {
+			return newMergeError(path, dst, src, err)
+		}
+		return nil
+	}
+	old := dst.Interface()
+	if err := fn(dst, src); err != nil {
+		return newMergeError(path, dst, src, err)
+	}
+	if !reflect.DeepEqual(old, dst.Interface()) {
+		record(config, path, reflect.ValueOf(old), dst, ReasonTransform)
+	}
+	return nil
+}
+
+// Config allows to customize Mergo's behaviour.
+type Config struct {
+	// Overwrite makes non-empty dst attributes be overridden by non-empty src attribute values.
+	Overwrite bool
+	// OverwriteWithEmptyValue makes empty src attribute values override
+	// dst ones too, see WithOverwriteWithEmptyValue.
+	OverwriteWithEmptyValue bool
+	// OverwriteExplicitZeros makes zero values pointed to by non-nil src
+	// pointers override dst ones, see WithOverwriteExplicitZeros.
+	OverwriteExplicitZeros bool
+	// AppendSlice makes slices be appended even when Overwrite is set.
+	AppendSlice bool
+	// DeepMerge makes pointers and map values, like structs in maps, be
+	// merged recursively even when Overwrite is set, instead of replaced.
+	DeepMerge bool
+	// Transformers customizes how values of specific types are merged.
+	Transformers Transformers
+	// DeepCopy makes values assigned from src be deep copies of them, so
+	// dst doesn't share pointers, maps or slices with src.
+	DeepCopy bool
+	// DryRun makes merge work on a copy of dst, leaving it untouched.
+	DryRun bool
+	// Strict makes merge fail with a *ConflictError when dst and src hold
+	// different non-empty values.
+	Strict bool
+	// Resolver chooses the value of fields where dst and src hold
+	// different non-empty values, see WithResolver.
+	Resolver func(path string, dst, src reflect.Value) (reflect.Value, error)
+	// EmptyFuncs decide whether values of their types are empty, see
+	// WithEmptyFunc.
+	EmptyFuncs map[reflect.Type]func(v reflect.Value) bool
+	// CompatibleStructs makes merge accept structs of different types,
+	// matching their fields by key, see WithCompatibleStructs.
+	CompatibleStructs bool
+	// NestedMaps makes Map convert nested structs to maps when mapping a
+	// struct to a map, see WithNestedMaps.
+	NestedMaps bool
+	// TagName is the struct tag, like json or yaml, Map reads keys from.
+	TagName string
+	// KeyNamer names the keys of maps in Map. If nil, LowerCamelCase is used.
+	KeyNamer KeyNamer
+	// Squash makes Map flatten embedded structs, mapping their fields as
+	// fields of the embedding struct.
+	Squash bool
+	// ErrorOnUnknownKeys makes Map fail with an *UnknownKeysError when src
+	// has keys not mapped to any field.
+	ErrorOnUnknownKeys bool
+	// WeakTypes makes Map convert values to the type of the fields they
+	// are mapped to, see WithWeakTypes.
+	WeakTypes bool
+	// SliceStrategy selects how slices are merged.
+	SliceStrategy SliceStrategy
+	// SliceEqual compares slice elements for SliceUnion. If nil,
+	// reflect.DeepEqual is used.
+	SliceEqual func(a, b interface{}) bool
+	// SliceKey is the name of the field identifying elements of slices of
+	// structs. If set, those slices are merged element by element.
+	SliceKey string
+
+	// replace and deep are set from field tags, see fieldConfig.
+	replace bool
+	deep    bool
+
+	// copies tracks values already deep copied, see deepCopy.
+	copies map[visit]reflect.Value
+	// changes collects assignments, see WithChanges.
+	changes *[]Change
+	// conflicts collects conflicts found in strict mode.
+	conflicts *[]Conflict
+	// compatibility collects src fields not merged using CompatibleStructs.
+	compatibility *CompatibilityError
+	// unknownKeys collects keys not mapped to any field by Map.
+	unknownKeys *[]string
+	// keyPath locates, by their keys, the nested map of src being mapped.
+	keyPath string
+	// layer is the index of the src being merged by MergeAll.
+	layer int
+}
+
+// Transformers allows to merge specific types differently than in the default behaviour.
+// In other words, now you can customize how some types are merged.
+type Transformers interface {
+	// Transformer returns the function used to merge values of type t,
+	// or nil to use the default behaviour.
+	Transformer(t reflect.Type) func(dst, src reflect.Value) error
+}
+
 // Merge will fill any empty for value type attributes on the dst struct using corresponding
-// src attributes if they themselves are not empty. dst and src must be valid same-type structs
-// and dst must be a pointer to struct.
+// src attributes if they themselves are not empty. dst and src must be valid same-type structs,
+// unless WithCompatibleStructs is used, and dst must be a pointer to struct.
 // It won't merge unexported (private) fields and will do recursively any exported field.
-func Merge(dst, src interface{}) error {
-	return merge(dst, src, false)
+// Its behaviour can be customized with options like WithOverride or WithAppendSlice.
+func Merge(dst, src interface{}, opts ...func(*Config)) error {
+	return merge(dst, src, opts...)
 }
 
 // MergeWithOverwrite will do the same as Merge except that non-empty dst attributes will be overriden by
 // non-empty src attribute values.
-func MergeWithOverwrite(dst, src interface{}) error {
-	return merge(dst, src, true)
+//
+// Deprecated: use Merge(dst, src, WithOverride) instead.
+func MergeWithOverwrite(dst, src interface{}, opts ...func(*Config)) error {
+	return merge(dst, src, append(opts, WithOverride)...)
+}
+
+// MergeAll merges every src in srcs into dst in order, as successive calls to Merge would do
+// sharing their options. Without WithOverride, dst's values take precedence over srcs' ones and
+// earlier srcs take precedence over later ones. With it, later srcs take precedence. Changes
+// reported with WithChanges have Layer set to the index in srcs of the src they come from.
+// Every src is checked before merging any: if one is invalid, like a src of another type than dst,
+// a *LayerError is returned and dst is left untouched.
+func MergeAll(dst interface{}, srcs []interface{}, opts ...func(*Config)) error {
+	return mergeAll(dst, srcs, opts...)
 }
 
-func merge(dst, src interface{}, overwrite bool) error {
+// MergeAllWithOverwrite will do the same as MergeAll except that non-empty dst attributes will be
+// overriden by non-empty src attribute values, so later srcs take precedence over earlier ones.
+func MergeAllWithOverwrite(dst interface{}, srcs []interface{}, opts ...func(*Config)) error {
+	return mergeAll(dst, srcs, append(opts, WithOverride)...)
+}
+
+// WithOverride will make merge override non-empty dst attributes with non-empty src attributes values.
+func WithOverride(config *Config) {
+	config.Overwrite = true
+}
+
+// WithOverwriteWithEmptyValue will make merge override dst attributes with src attributes values,
+// even empty ones, so src always wins, e.g. setting Enabled to false or Retries to 0.
+func WithOverwriteWithEmptyValue(config *Config) {
+	config.Overwrite = true
+	config.OverwriteWithEmptyValue = true
+}
+
+// WithOverwriteExplicitZeros will make merge override non-empty dst attributes with non-empty src
+// attributes values, as WithOverride does, and also with zero values set explicitly through non-nil
+// pointers, like a *bool pointing to false, even when pointers are merged recursively, as with
+// WithDeepMerge or WithStrict. dst's pointer is then replaced by src's.
+func WithOverwriteExplicitZeros(config *Config) {
+	config.Overwrite = true
+	config.OverwriteExplicitZeros = true
+}
+
+// WithAppendSlice will make merge append slices instead of overwriting them when WithOverride is used.
+func WithAppendSlice(config *Config) {
+	config.AppendSlice = true
+}
+
+// WithDeepMerge will make merge merge pointers' values and map values, like structs inside
+// maps, recursively when WithOverride is used, instead of replacing them, as the deep tag does
+// for a single field.
+func WithDeepMerge(config *Config) {
+	config.DeepMerge = true
+}
+
+// WithTransformers adds transformers to merge, allowing to customize the merging of some types.
+func WithTransformers(transformers Transformers) func(*Config) {
+	return func(config *Config) {
+		config.Transformers = transformers
+	}
+}
+
+// WithDeepCopy will make merge assign deep copies of src values, instead of sharing their
+// pointers, maps and slices with dst.
+func WithDeepCopy(config *Config) {
+	config.DeepCopy = true
+}
+
+// WithSliceStrategy will make merge combine non-empty slices using strategy.
+func WithSliceStrategy(strategy SliceStrategy) func(*Config) {
+	return func(config *Config) {
+		config.SliceStrategy = strategy
+	}
+}
+
+// WithSliceEqual will make merge compare slice elements using equal when deduplicating them
+// with SliceUnion.
+func WithSliceEqual(equal func(a, b interface{}) bool) func(*Config) {
+	return func(config *Config) {
+		config.SliceEqual = equal
+	}
+}
+
+// WithSliceKey will make merge match elements of slices of structs by their field named key,
+// merging matching elements and appending the others.
+func WithSliceKey(key string) func(*Config) {
+	return func(config *Config) {
+		config.SliceKey = key
+	}
+}
+
+func newConfig(opts []func(*Config)) *Config {
+	config := &Config{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if config.DeepCopy {
+		config.copies = make(map[visit]reflect.Value)
+	}
+	if config.Strict {
+		config.conflicts = new([]Conflict)
+	}
+	if config.CompatibleStructs {
+		config.compatibility = &CompatibilityError{}
+	}
+	if config.ErrorOnUnknownKeys {
+		config.unknownKeys = new([]string)
+	}
+	if config.Strict || config.Resolver != nil {
+		// Conflicts are found on leaves.
+		config.deep = true
+	}
+	if config.DeepMerge {
+		config.deep = true
+	}
+	return config
+}
+
+func merge(dst, src interface{}, opts ...func(*Config)) error {
 	var (
 		vDst, vSrc reflect.Value
 		err        error
 	)
+	config := newConfig(opts)
 	if vDst, vSrc, err = resolveValues(dst, src); err != nil {
 		return err
 	}
-	if vDst.Type() != vSrc.Type() {
+	if vDst.Type() != vSrc.Type() && !compatibleTypes(vDst.Type(), vSrc.Type(), config) {
 		return ErrDifferentArgumentsTypes
 	}
-	return deepMerge(vDst, vSrc, make(map[visit]bool), 0, overwrite)
+	vDst = dryRunTarget(vDst, config)
+	if err = deepMerge(vDst, vSrc, make(map[visit]bool), 0, "", config); err != nil {
+		return err
+	}
+	if err = conflictError(config); err != nil {
+		return err
+	}
+	return compatibilityError(config)
+}
+
+func mergeAll(dst interface{}, srcs []interface{}, opts ...func(*Config)) error {
+	var (
+		vDst reflect.Value
+		err  error
+	)
+	if len(srcs) == 0 {
+		return nil
+	}
+	config := newConfig(opts)
+	// Every src is checked first, so dst is left untouched if one is invalid.
+	vSrcs := make([]reflect.Value, len(srcs))
+	for i, src := range srcs {
+		var v, vSrc reflect.Value
+		if v, vSrc, err = resolveValues(dst, src); err != nil {
+			return &LayerError{i, err}
+		}
+		if v.Type() != vSrc.Type() && !compatibleTypes(v.Type(), vSrc.Type(), config) {
+			return &LayerError{i, newMergeError("", v, vSrc, ErrDifferentArgumentsTypes)}
+		}
+		vDst, vSrcs[i] = v, vSrc
+	}
+	vDst = dryRunTarget(vDst, config)
+	visited := make(map[visit]bool)
+	for i, vSrc := range vSrcs {
+		config.layer = i
+		if err = deepMerge(vDst, vSrc, visited, 0, "", config); err != nil {
+			return err
+		}
+	}
+	if err = conflictError(config); err != nil {
+		return err
+	}
+	return compatibilityError(config)
 }
@@ -0,0 +1,66 @@
+// Copyright 2013 Dario Castañé. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mergo
+
+import (
+	"reflect"
+)
+
+// Merger is implemented by types knowing how to merge themselves, like a set
+// of labels or a retry policy keeping the stricter limit. When dst, or a
+// field, map value or value pointed to in it, implements Merger, with a value
+// or pointer receiver, its MergeFrom method is called with the src value of
+// the same type instead of merging them as Mergo does by default. It is only
+// called when both are non-empty: an empty dst is filled with src as usual.
+// overwrite tells whether WithOverride was used.
+type Merger interface {
+	MergeFrom(src interface{}, overwrite bool) error
+}
+
+var mergerType = reflect.TypeOf((*Merger)(nil)).Elem()
+
+// isMerger reports whether values of type t, or pointers to them, implement
+// Merger.
+func isMerger(t reflect.Type) bool {
+	return t.Implements(mergerType) || reflect.PtrTo(t).Implements(mergerType)
+}
+
+// merger returns the Merger dst's value, or its address, is, if any.
+// Pointers and interfaces are left to be merged through the values they
+// hold, so MergeFrom always gets a src of its receiver's type.
+func merger(dst reflect.Value) (Merger, bool) {
+	if k := dst.Kind(); k == reflect.Ptr || k == reflect.Interface || !dst.CanInterface() {
+		return nil, false
+	}
+	if dst.CanAddr() {
+		if m, ok := dst.Addr().Interface().(Merger); ok {
+			return m, true
+		}
+	}
+	m, ok := dst.Interface().(Merger)
+	return m, ok
+}
+
+// mergeFrom merges src into dst using m, dst's Merger, recording the change
+// if any.
+func mergeFrom(m Merger, dst, src reflect.Value, path string, config *Config) error {
+	if !src.CanInterface() {
+		return nil
+	}
+	if config.changes == nil {
+		if err := m.MergeFrom(src.Interface(), config.Overwrite); err != nil {
+			return newMergeError(path, dst, src, err)
+		}
+		return nil
+	}
+	old := deepCopy(dst, make(map[visit]reflect.Value))
+	if err := m.MergeFrom(src.Interface(), config.Overwrite); err != nil {
+		return newMergeError(path, dst, src, err)
+	}
+	if !reflect.DeepEqual(old.Interface(), dst.Interface()) {
+		record(config, path, old, dst, ReasonMerger)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package mergo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type labelSet map[string]bool
+
+func (l labelSet) MergeFrom(src interface{}, overwrite bool) error {
+	for label := range src.(labelSet) {
+		l[label] = true
+	}
+	return nil
+}
+
+type retryPolicy struct {
+	MaxAttempts int
+}
+
+// MergeFrom keeps the stricter limit.
+func (p *retryPolicy) MergeFrom(src interface{}, overwrite bool) error {
+	s := src.(retryPolicy)
+	if s.MaxAttempts < 0 {
+		return errors.New("negative attempts")
+	}
+	if p.MaxAttempts == 0 || s.MaxAttempts != 0 && s.MaxAttempts < p.MaxAttempts {
+		p.MaxAttempts = s.MaxAttempts
+	}
+	return nil
+}
+
+type mergerTest struct {
+	Labels   labelSet
+	Retry    retryPolicy
+	Fallback *retryPolicy
+	Routes   map[string]retryPolicy
+}
+
+func TestMerger(t *testing.T) {
+	dst := mergerTest{
+		Labels:   labelSet{"a": true},
+		Retry:    retryPolicy{3},
+		Fallback: &retryPolicy{5},
+		Routes:   map[string]retryPolicy{"/": {4}},
+	}
+	src := mergerTest{
+		Labels:   labelSet{"b": true},
+		Retry:    retryPolicy{5},
+		Fallback: &retryPolicy{2},
+		Routes:   map[string]retryPolicy{"/": {1}, "/api": {7}},
+	}
+	fallback := dst.Fallback
+	var changes []Change
+	if err := Merge(&dst, src, WithOverride, WithChanges(&changes)); err != nil {
+		t.Fatal(err)
+	}
+	expected := mergerTest{
+		Labels:   labelSet{"a": true, "b": true},
+		Retry:    retryPolicy{3},
+		Fallback: &retryPolicy{2},
+		Routes:   map[string]retryPolicy{"/": {1}, "/api": {7}},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("got %+v expected %+v", dst, expected)
+	}
+	if dst.Fallback != fallback {
+		t.Fatalf("dst.Fallback should be merged in place")
+	}
+	if len(changes) == 0 || changes[0].Path != "Labels" || changes[0].Reason != ReasonMerger {
+		t.Fatalf("expected a merger change on Labels first, got %+v", changes)
+	}
+}
+
+func TestMergerError(t *testing.T) {
+	dst := mergerTest{Retry: retryPolicy{3}}
+	src := mergerTest{Retry: retryPolicy{-1}}
+	err := Merge(&dst, src)
+	if mergeErr, ok := err.(*MergeError); !ok || mergeErr.Path != "Retry" {
+		t.Fatalf("expected a MergeError at Retry, got %v", err)
+	}
+}
+
+func TestMergerEmptyDst(t *testing.T) {
+	var dst mergerTest
+	src := mergerTest{
+		Labels:   labelSet{"b": true},
+		Retry:    retryPolicy{5},
+		Fallback: &retryPolicy{2},
+	}
+	if err := Merge(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	expected := mergerTest{
+		Labels:   labelSet{"b": true},
+		Retry:    retryPolicy{5},
+		Fallback: &retryPolicy{2},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("got %+v expected %+v", dst, expected)
+	}
+}
@@ -10,6 +10,7 @@ package mergo
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 )
 
@@ -20,8 +21,62 @@ var (
 	ErrNotSupported                = errors.New("only structs and maps are supported")
 	ErrExpectedMapAsDestination    = errors.New("dst was expected to be a map")
 	ErrExpectedStructAsDestination = errors.New("dst was expected to be a struct")
+	ErrInvalidConversion           = errors.New("value can't be converted")
 )
 
+// MergeError is returned when merging the values found at Path fails.
+// It wraps the cause, so errors.Is can match it against the errors above.
+type MergeError struct {
+	// Path locates the values from the root of dst, e.g. Servers[2].TLS.Cert.
+	Path string
+	// DstType and SrcType are the types of the values.
+	DstType, SrcType reflect.Type
+	Err              error
+}
+
+func (e *MergeError) Error() string {
+	msg := e.Err.Error()
+	if e.DstType != nil && e.SrcType != nil && e.DstType != e.SrcType {
+		msg = fmt.Sprintf("%s (%s) != (%s)", msg, e.SrcType, e.DstType)
+	}
+	if e.Path != "" {
+		msg = e.Path + ": " + msg
+	}
+	return msg
+}
+
+// Unwrap returns the cause of the error.
+func (e *MergeError) Unwrap() error {
+	return e.Err
+}
+
+// LayerError is returned by MergeAll when the src at index Layer of srcs is
+// invalid, before merging any of them into dst. It wraps the cause, like a
+// *MergeError when the types of dst and src differ.
+type LayerError struct {
+	Layer int
+	Err   error
+}
+
+func (e *LayerError) Error() string {
+	return fmt.Sprintf("srcs[%d]: %v", e.Layer, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *LayerError) Unwrap() error {
+	return e.Err
+}
+
+// newMergeError returns err wrapped in a *MergeError for the values dst
+// and src at path, unless it already is one.
+func newMergeError(path string, dst, src reflect.Value, err error) error {
+	var mergeErr *MergeError
+	if errors.As(err, &mergeErr) {
+		return err
+	}
+	return &MergeError{path, dst.Type(), src.Type(), err}
+}
+
 // Taken from reflect.DeepEqual
 // During deepMerge, must keep track of all merges  that are
 // in progress. The merge algorithm assumes that all
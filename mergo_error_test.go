@@ -0,0 +1,50 @@
+package mergo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMergeErrorPath(t *testing.T) {
+	type inner struct{ Value interface{} }
+	type outer struct{ Servers []inner }
+	dst := outer{[]inner{{1}, {2}, {3}}}
+	src := outer{[]inner{{1}, {2}, {"three"}}}
+	err := Merge(&dst, src, WithSliceStrategy(SliceByIndex))
+	if !errors.Is(err, ErrDifferentArgumentsTypes) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var mergeErr *MergeError
+	if !errors.As(err, &mergeErr) {
+		t.Fatalf("expected a *MergeError, got %v", err)
+	}
+	if mergeErr.Path != "Servers[2].Value" {
+		t.Fatalf("unexpected path: %s", mergeErr.Path)
+	}
+	if mergeErr.DstType != reflect.TypeOf(0) || mergeErr.SrcType != reflect.TypeOf("") {
+		t.Fatalf("unexpected types: %v, %v", mergeErr.DstType, mergeErr.SrcType)
+	}
+}
+
+func TestMapErrorPath(t *testing.T) {
+	var dst moreComplextText
+	src := map[string]interface{}{
+		"ct": map[string]interface{}{
+			"st": map[string]interface{}{
+				"value": "42",
+			},
+		},
+	}
+	err := Map(&dst, src)
+	if !errors.Is(err, ErrDifferentArgumentsTypes) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var mergeErr *MergeError
+	if !errors.As(err, &mergeErr) || mergeErr.Path != "Ct.St.Value" {
+		t.Fatalf("expected a *MergeError on Ct.St.Value, got %v", err)
+	}
+	if got := err.Error(); got != "Ct.St.Value: src and dst must be of same type (string) != (int)" {
+		t.Fatalf("unexpected message: %s", got)
+	}
+}
@@ -6,6 +6,7 @@
 package mergo
 
 import (
+	"errors"
 	"io/ioutil"
 	"reflect"
 	"runtime/debug"
@@ -862,3 +863,326 @@ func TestBooleanPointer(t *testing.T) {
 		t.Fatalf("dst.C should be true")
 	}
 }
+
+func TestBooleanPointerWithOverwriteExplicitZeros(t *testing.T) {
+	bt, bf := true, false
+	src := structWithBoolPointer{
+		&bf,
+	}
+	dst := structWithBoolPointer{
+		&bt,
+	}
+	shared := structWithBoolPointer{
+		&bt,
+	}
+	if err := Merge(&dst, src, WithOverwriteExplicitZeros, WithDeepMerge); err != nil {
+		t.FailNow()
+	}
+	if *dst.C != *src.C {
+		t.Fatalf("dst.C should be false")
+	}
+	if !bt || !*shared.C {
+		t.Fatalf("value dst.C pointed to, shared with another value, should be left untouched")
+	}
+	var unset structWithBoolPointer
+	if err := Merge(&dst, unset, WithOverwriteExplicitZeros); err != nil {
+		t.FailNow()
+	}
+	if dst.C == nil {
+		t.Fatalf("dst.C should not be overwritten by a nil pointer")
+	}
+	dst.C = &bt
+	if err := Merge(&dst, src, WithDeepMerge, WithOverride); err != nil {
+		t.FailNow()
+	}
+	if !*dst.C {
+		t.Fatalf("dst.C should be true without WithOverwriteExplicitZeros")
+	}
+}
+
+func TestOverwriteExplicitZerosUnexportedPointer(t *testing.T) {
+	type withUnexportedPointer struct {
+		p *int
+		A int
+	}
+	one, zero := 1, 0
+	dst := withUnexportedPointer{&one, 1}
+	src := withUnexportedPointer{&zero, 2}
+	if err := Merge(&dst, src, WithOverwriteExplicitZeros, WithStrict); err == nil {
+		t.Fatalf("expected a conflict on A")
+	}
+	if dst.p != &one || one != 1 {
+		t.Fatalf("unexported pointer changed")
+	}
+}
+
+func TestMergeWithOverwriteWithEmptyValue(t *testing.T) {
+	a := complexTest{simpleTest{1}, 1, "overwritten-with-empty-value"}
+	b := complexTest{simpleTest{0}, 2, ""}
+	expect := complexTest{simpleTest{0}, 1, ""}
+	if err := Merge(&a, b, WithOverwriteWithEmptyValue); err != nil {
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(a, expect) {
+		t.Fatalf("Test failed:\ngot  :\n%#v\n\nwant :\n%#v\n\n", a, expect)
+	}
+}
+
+func TestMergeWithOverrideOption(t *testing.T) {
+	a := complexTest{simpleTest{1}, 1, "do-not-overwrite-with-empty-value"}
+	b := complexTest{simpleTest{42}, 2, ""}
+	expect := complexTest{simpleTest{42}, 1, "do-not-overwrite-with-empty-value"}
+	if err := Merge(&a, b, WithOverride); err != nil {
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(a, expect) {
+		t.Fatalf("Test failed:\ngot  :\n%+v\n\nwant :\n%+v\n\n", a, expect)
+	}
+}
+
+func TestMergeWithAppendSlice(t *testing.T) {
+	a := sliceTest{[]int{1}}
+	b := sliceTest{[]int{2, 3}}
+	if err := Merge(&a, b, WithOverride); err != nil {
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(a.S, []int{2, 3}) {
+		t.Fatalf("slice not overwritten: %v", a.S)
+	}
+	a = sliceTest{[]int{1}}
+	if err := Merge(&a, b, WithOverride, WithAppendSlice); err != nil {
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(a.S, []int{1, 2, 3}) {
+		t.Fatalf("slice not appended: %v", a.S)
+	}
+}
+
+func TestMapWithOverrideOption(t *testing.T) {
+	a := simpleTest{1}
+	b := map[string]interface{}{
+		"value": 42,
+	}
+	if err := Map(&a, b); err != nil {
+		t.FailNow()
+	}
+	if a.Value != 1 {
+		t.Fatalf("a.Value overwritten unexpectedly: a.Value(%d)", a.Value)
+	}
+	if err := Map(&a, b, WithOverride); err != nil {
+		t.FailNow()
+	}
+	if a.Value != 42 {
+		t.Fatalf("b not merged in properly: a.Value(%d) != b.Value(%v)", a.Value, b["value"])
+	}
+}
+
+type timeTransformer struct{}
+
+func (timeTransformer) Transformer(typ reflect.Type) func(dst, src reflect.Value) error {
+	if typ != reflect.TypeOf(time.Time{}) {
+		return nil
+	}
+	return func(dst, src reflect.Value) error {
+		if dst.Interface().(time.Time).IsZero() {
+			dst.Set(src)
+		}
+		return nil
+	}
+}
+
+// earliestTimeTransformer keeps the earliest non-zero time, counting its calls.
+type earliestTimeTransformer struct {
+	calls int
+}
+
+func (e *earliestTimeTransformer) Transformer(typ reflect.Type) func(dst, src reflect.Value) error {
+	if typ != reflect.TypeOf(time.Time{}) {
+		return nil
+	}
+	return func(dst, src reflect.Value) error {
+		e.calls++
+		d, s := dst.Interface().(time.Time), src.Interface().(time.Time)
+		if d.IsZero() || !s.IsZero() && s.Before(d) {
+			dst.Set(src)
+		}
+		return nil
+	}
+}
+
+type structWithTime struct {
+	Birth time.Time
+	Name  string
+}
+
+func TestMergeWithTransformers(t *testing.T) {
+	now := time.Now()
+	dst := structWithTime{}
+	src := structWithTime{now, "foo"}
+	if err := Merge(&dst, src); err != nil {
+		t.FailNow()
+	}
+	// time.Time has an IsZero method, so it is filled when zero.
+	if !dst.Birth.Equal(now) {
+		t.Fatalf("time.Time not filled without transformer: dst.Birth(%v) != src.Birth(%v)", dst.Birth, src.Birth)
+	}
+	dst = structWithTime{}
+	if err := Merge(&dst, src, WithTransformers(timeTransformer{})); err != nil {
+		t.FailNow()
+	}
+	if !dst.Birth.Equal(now) {
+		t.Fatalf("time.Time not merged in properly: dst.Birth(%v) != src.Birth(%v)", dst.Birth, src.Birth)
+	}
+	if dst.Name != "foo" {
+		t.Fatalf("b not merged in properly: dst.Name(%s) != src.Name(%s)", dst.Name, src.Name)
+	}
+	// Without a transformer, a non-zero time.Time, having only unexported
+	// fields, is never overwritten.
+	earlier := structWithTime{now.Add(-time.Hour), ""}
+	if err := Merge(&dst, earlier, WithOverride); err != nil {
+		t.FailNow()
+	}
+	if !dst.Birth.Equal(now) {
+		t.Fatalf("time.Time overwritten without transformer: dst.Birth(%v) != %v", dst.Birth, now)
+	}
+	transformer := &earliestTimeTransformer{}
+	if err := Merge(&dst, earlier, WithOverride, WithTransformers(transformer)); err != nil {
+		t.FailNow()
+	}
+	if transformer.calls != 1 {
+		t.Fatalf("transformer called %d times, expected 1", transformer.calls)
+	}
+	if !dst.Birth.Equal(earlier.Birth) {
+		t.Fatalf("time.Time not merged by the transformer: dst.Birth(%v) != %v", dst.Birth, earlier.Birth)
+	}
+}
+
+func TestMergeWithTransformersInMaps(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	dst := map[string]time.Time{"a": earlier}
+	src := map[string]time.Time{"a": now, "b": now}
+	transformer := &earliestTimeTransformer{}
+	if err := Merge(&dst, src, WithOverride, WithTransformers(transformer)); err != nil {
+		t.Fatal(err)
+	}
+	if transformer.calls != 2 {
+		t.Fatalf("transformer called %d times, expected 2", transformer.calls)
+	}
+	if !dst["a"].Equal(earlier) || !dst["b"].Equal(now) {
+		t.Fatalf("map values not merged by the transformer: %v", dst)
+	}
+}
+
+func TestMergeAll(t *testing.T) {
+	defaults := complexTest{simpleTest{1}, 0, "default"}
+	file := complexTest{simpleTest{2}, 0, ""}
+	flags := complexTest{simpleTest{}, 0, "flag"}
+	var dst complexTest
+	if err := MergeAll(&dst, []interface{}{flags, file, defaults}); err != nil {
+		t.Fatal(err)
+	}
+	expected := complexTest{simpleTest{2}, 0, "flag"}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Test failed:\ngot  :\n%+v\n\nwant :\n%+v\n\n", dst, expected)
+	}
+
+	dst = complexTest{}
+	var changes []Change
+	if err := MergeAllWithOverwrite(&dst, []interface{}{defaults, file, flags}, WithChanges(&changes)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Test failed:\ngot  :\n%+v\n\nwant :\n%+v\n\n", dst, expected)
+	}
+	layers := make(map[string]int)
+	for _, c := range changes {
+		layers[c.Path] = c.Layer
+	}
+	if !reflect.DeepEqual(layers, map[string]int{"St.Value": 1, "ID": 2}) {
+		t.Fatalf("unexpected layers: %+v", layers)
+	}
+}
+
+func TestMergeAllDifferentTypes(t *testing.T) {
+	var dst simpleTest
+	err := MergeAll(&dst, []interface{}{simpleTest{1}, 42})
+	if layerErr, ok := err.(*LayerError); !ok || layerErr.Layer != 1 || !errors.Is(err, ErrDifferentArgumentsTypes) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Value != 0 {
+		t.Fatalf("dst merged before all srcs were checked: %+v", dst)
+	}
+}
+
+func TestMergeStructsInMaps(t *testing.T) {
+	type endpoint struct {
+		Host    string
+		Port    int
+		Retries *int
+	}
+	retries := 3
+	dst := map[string]endpoint{
+		"api": {Host: "api.local"},
+		"db":  {Host: "db.local", Port: 5432},
+	}
+	src := map[string]endpoint{
+		"api": {Host: "api.example.com", Port: 443, Retries: &retries},
+		"db":  {Port: 5433},
+	}
+	if err := Merge(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]endpoint{
+		"api": {Host: "api.local", Port: 443, Retries: &retries},
+		"db":  {Host: "db.local", Port: 5432},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("got %+v expected %+v", dst, expected)
+	}
+	if err := Merge(&dst, src, WithOverride, WithDeepMerge); err != nil {
+		t.Fatal(err)
+	}
+	expected["api"] = endpoint{Host: "api.example.com", Port: 443, Retries: &retries}
+	expected["db"] = endpoint{Host: "db.local", Port: 5433}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("got %+v expected %+v", dst, expected)
+	}
+}
+
+func TestMergeMapValuesOfDifferentTypes(t *testing.T) {
+	dst := map[string]interface{}{"port": 80, "host": "a"}
+	src := map[string]interface{}{"port": "8080", "host": nil}
+	if err := Merge(&dst, src, WithOverride, WithDeepMerge); err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]interface{}{"port": "8080", "host": "a"}; !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("got %v expected %v", dst, expected)
+	}
+	dst = map[string]interface{}{"port": 80}
+	err := Merge(&dst, src, WithStrict)
+	if conflictErr, ok := err.(*ConflictError); !ok || len(conflictErr.Conflicts) != 1 || conflictErr.Conflicts[0].Path != `["port"]` {
+		t.Fatalf("expected a conflict on [\"port\"], got %v", err)
+	}
+	var paths []string
+	keep := func(path string, dst, src reflect.Value) (reflect.Value, error) {
+		paths = append(paths, path)
+		return reflect.Value{}, nil
+	}
+	if err := Merge(&dst, src, WithResolver(keep)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(paths, []string{`["port"]`}) || dst["port"] != 80 {
+		t.Fatalf("resolver not called on values of different types: %v %v", paths, dst)
+	}
+}
+
+func TestMergeStructsInMapsError(t *testing.T) {
+	type mapWithStruct map[string]struct{ Value interface{} }
+	dst := mapWithStruct{"a": {Value: 1}}
+	src := mapWithStruct{"a": {Value: "1"}}
+	err := Merge(&dst, src)
+	if mergeErr, ok := err.(*MergeError); !ok || mergeErr.Path != `["a"].Value` {
+		t.Fatalf("expected a MergeError at [\"a\"].Value, got %v", err)
+	}
+}
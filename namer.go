@@ -0,0 +1,102 @@
+// Copyright 2013 Dario Castañé. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mergo
+
+import (
+	"strings"
+	"unicode"
+)
+
+// KeyNamer names the keys struct fields are mapped to by Map, in both directions.
+type KeyNamer interface {
+	// Key returns the map key for the field named name.
+	Key(name string) string
+	// Match reports whether key refers to the field named name.
+	Match(key, name string) bool
+}
+
+// Key namers provided by Mergo.
+var (
+	// LowerCamelCase maps MaxIdleConns to maxIdleConns. It is the default.
+	// It also matches keys equal to field names, as MaxIdleConns.
+	LowerCamelCase KeyNamer = lowerCamelCase{}
+	// SnakeCase maps MaxIdleConns to max_idle_conns.
+	SnakeCase KeyNamer = separatedCase('_')
+	// KebabCase maps MaxIdleConns to max-idle-conns.
+	KebabCase KeyNamer = separatedCase('-')
+	// CaseInsensitive maps MaxIdleConns to maxIdleConns, like LowerCamelCase,
+	// but matches keys ignoring case, underscores and hyphens, as
+	// max_idle_conns, MAX-IDLE-CONNS or maxidleconns.
+	CaseInsensitive KeyNamer = caseInsensitive{}
+)
+
+// WithKeyNamer will make Map name keys using namer instead of LowerCamelCase.
+func WithKeyNamer(namer KeyNamer) func(*Config) {
+	return func(config *Config) {
+		config.KeyNamer = namer
+	}
+}
+
+// keyNamer returns the KeyNamer set in config, or the default one.
+func keyNamer(config *Config) KeyNamer {
+	if config.KeyNamer == nil {
+		return LowerCamelCase
+	}
+	return config.KeyNamer
+}
+
+type lowerCamelCase struct{}
+
+func (lowerCamelCase) Key(name string) string {
+	return changeInitialCase(name, unicode.ToLower)
+}
+
+func (lowerCamelCase) Match(key, name string) bool {
+	return changeInitialCase(key, unicode.ToUpper) == name
+}
+
+type separatedCase rune
+
+func (sep separatedCase) Key(name string) string {
+	words := splitWords(name)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return strings.Join(words, string(sep))
+}
+
+func (sep separatedCase) Match(key, name string) bool {
+	return key == sep.Key(name)
+}
+
+type caseInsensitive struct{}
+
+func (caseInsensitive) Key(name string) string {
+	return LowerCamelCase.Key(name)
+}
+
+func (caseInsensitive) Match(key, name string) bool {
+	return strings.EqualFold(strings.NewReplacer("_", "", "-", "").Replace(key), name)
+}
+
+// splitWords splits a camel case name into its words, keeping acronyms
+// together: ServerHTTPPort is split into Server, HTTP and Port.
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		if !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		prev := runes[i-1]
+		nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
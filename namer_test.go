@@ -0,0 +1,58 @@
+package mergo
+
+import (
+	"reflect"
+	"testing"
+)
+
+type namerTest struct {
+	MaxIdleConns   int
+	ServerHTTPPort int
+	ID             string
+}
+
+func TestKeyNamers(t *testing.T) {
+	cases := []struct {
+		namer KeyNamer
+		keys  []string
+	}{
+		{LowerCamelCase, []string{"maxIdleConns", "serverHTTPPort", "iD"}},
+		{SnakeCase, []string{"max_idle_conns", "server_http_port", "id"}},
+		{KebabCase, []string{"max-idle-conns", "server-http-port", "id"}},
+		{CaseInsensitive, []string{"maxIdleConns", "serverHTTPPort", "iD"}},
+	}
+	src := namerTest{1, 2, "foo"}
+	for _, c := range cases {
+		m := make(map[string]interface{})
+		if err := Map(&m, src, WithKeyNamer(c.namer)); err != nil {
+			t.Fatal(err)
+		}
+		expected := map[string]interface{}{c.keys[0]: 1, c.keys[1]: 2, c.keys[2]: "foo"}
+		if !reflect.DeepEqual(m, expected) {
+			t.Errorf("got %+v expected %+v", m, expected)
+			continue
+		}
+		var dst namerTest
+		if err := Map(&dst, m, WithKeyNamer(c.namer)); err != nil {
+			t.Fatal(err)
+		}
+		if dst != src {
+			t.Errorf("got %+v expected %+v", dst, src)
+		}
+	}
+}
+
+func TestCaseInsensitiveKeyNamer(t *testing.T) {
+	src := map[string]interface{}{
+		"MAX_IDLE_CONNS":   1,
+		"server-http-port": 2,
+		"id":               "foo",
+	}
+	var dst namerTest
+	if err := Map(&dst, src, WithKeyNamer(CaseInsensitive)); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (namerTest{1, 2, "foo"}); dst != expected {
+		t.Fatalf("got %+v expected %+v", dst, expected)
+	}
+}
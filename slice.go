@@ -0,0 +1,180 @@
+// Copyright 2013 Dario Castañé. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mergo
+
+import (
+	"reflect"
+)
+
+// SliceStrategy selects how two non-empty slices are merged.
+type SliceStrategy int
+
+const (
+	// SliceDefault appends src to dst, unless overriding without
+	// AppendSlice set, in which case dst is replaced by src.
+	SliceDefault SliceStrategy = iota
+	// SliceAppend appends src's elements to dst.
+	SliceAppend
+	// SliceReplace replaces dst by src.
+	SliceReplace
+	// SliceUnion appends src's elements not already present in dst.
+	// Elements are compared with Config.SliceEqual, or reflect.DeepEqual
+	// if it is nil.
+	SliceUnion
+	// SliceByIndex merges each src[i] into dst[i], appending src's
+	// elements beyond dst's length.
+	SliceByIndex
+)
+
+// sliceStrategy returns the strategy to merge slices with under config,
+// resolving SliceDefault.
+func sliceStrategy(config *Config) SliceStrategy {
+	if config.SliceStrategy != SliceDefault {
+		return config.SliceStrategy
+	}
+	if !config.Overwrite || config.AppendSlice {
+		return SliceAppend
+	}
+	return SliceReplace
+}
+
+// mergesSlices reports whether dst and src, elements of a map, are slices
+// to be merged according to config instead of replaced.
+func mergesSlices(dst, src reflect.Value, config *Config) bool {
+	if dst.Kind() == reflect.Interface {
+		dst = dst.Elem()
+	}
+	if src.Kind() == reflect.Interface {
+		src = src.Elem()
+	}
+	if dst.Kind() != reflect.Slice || src.Kind() != reflect.Slice {
+		return false
+	}
+	return sliceStrategy(config) != SliceReplace || config.SliceKey != ""
+}
+
+// Merges src into dst, both non-empty slices, according to config.
+func mergeSlices(dst, src reflect.Value, visited map[visit]bool, depth int, path string, config *Config) error {
+	if config.SliceKey != "" && hasKeyField(dst.Type().Elem(), config.SliceKey) {
+		return mergeSliceByKey(dst, src, visited, depth, path, config)
+	}
+	if config.SliceStrategy == SliceDefault && !config.AppendSlice && (config.Strict || config.Resolver != nil) {
+		// Without a strategy chosen for them, slices are compared as a whole,
+		// as any other value, whether overriding or not.
+		return resolve(dst, src, path, config.Overwrite, config)
+	}
+	switch sliceStrategy(config) {
+	case SliceAppend:
+		set(dst, reflect.AppendSlice(dst, copyOf(src, config)), path, ReasonAppend, config)
+	case SliceUnion:
+		result := dst
+		for i, n := 0, src.Len(); i < n; i++ {
+			if !containsElement(result, src.Index(i), config) {
+				result = reflect.Append(result, copyOf(src.Index(i), config))
+			}
+		}
+		if result.Len() > dst.Len() {
+			set(dst, result, path, ReasonAppend, config)
+		}
+	case SliceByIndex:
+		for i, n := 0, src.Len(); i < n && i < dst.Len(); i++ {
+			if err := deepMerge(dst.Index(i), src.Index(i), visited, depth+1, indexPath(path, i), config); err != nil {
+				return err
+			}
+		}
+		if src.Len() > dst.Len() {
+			set(dst, reflect.AppendSlice(dst, copyOf(src.Slice(dst.Len(), src.Len()), config)), path, ReasonAppend, config)
+		}
+	default:
+		return resolve(dst, src, path, true, config)
+	}
+	return nil
+}
+
+// containsElement reports whether s contains an element equal to e.
+func containsElement(s, e reflect.Value, config *Config) bool {
+	equal := config.SliceEqual
+	if equal == nil {
+		equal = reflect.DeepEqual
+	}
+	for i, n := 0, s.Len(); i < n; i++ {
+		if equal(s.Index(i).Interface(), e.Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasKeyField reports whether elements of type typ, structs or pointers to
+// structs, have an exported field named key.
+func hasKeyField(typ reflect.Type, key string) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+	field, ok := typ.FieldByName(key)
+	return ok && isExported(field)
+}
+
+// keyOf returns the value of the key field of a slice element, or an invalid
+// value if the element is a nil pointer.
+func keyOf(element reflect.Value, key string) reflect.Value {
+	if element.Kind() == reflect.Ptr {
+		if element.IsNil() {
+			return reflect.Value{}
+		}
+		element = element.Elem()
+	}
+	return element.FieldByName(key)
+}
+
+// indexByKey returns the index of the first element in s whose key field
+// equals k, or -1 if there is none.
+func indexByKey(s reflect.Value, key string, k reflect.Value) int {
+	for i, n := 0, s.Len(); i < n; i++ {
+		if ek := keyOf(s.Index(i), key); ek.IsValid() && reflect.DeepEqual(ek.Interface(), k.Interface()) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Merges src's elements into dst matching them by their key field, like
+// Kubernetes' strategic merge patch does. Matching elements are merged
+// recursively and unmatched ones, even sharing a key, are appended to dst.
+func mergeSliceByKey(dst, src reflect.Value, visited map[visit]bool, depth int, path string, config *Config) error {
+	key := config.SliceKey
+	result := dst
+	for i, n := 0, src.Len(); i < n; i++ {
+		srcElement := src.Index(i)
+		k := keyOf(srcElement, key)
+		if !k.IsValid() {
+			result = reflect.Append(result, copyOf(srcElement, config))
+			continue
+		}
+		// Only dst's own elements are matched, so appended src elements
+		// are never merged into, which would change src's pointees.
+		j := indexByKey(dst, key, k)
+		if j < 0 {
+			result = reflect.Append(result, copyOf(srcElement, config))
+			continue
+		}
+		dstElement := result.Index(j)
+		elementPath := indexPath(path, j)
+		if dstElement.Kind() == reflect.Ptr {
+			// Matching elements are always merged, never replaced.
+			dstElement, srcElement = dstElement.Elem(), srcElement.Elem()
+		}
+		if err := deepMerge(dstElement, srcElement, visited, depth+1, elementPath, config); err != nil {
+			return err
+		}
+	}
+	if result.Len() > dst.Len() {
+		set(dst, result, path, ReasonAppend, config)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package mergo
+
+import (
+	"reflect"
+	"testing"
+)
+
+type container struct {
+	Name  string
+	Image string
+	Port  int
+}
+
+type pod struct {
+	Containers []container `mergo:"key=Name"`
+	Sidecars   []*container
+}
+
+func TestMergeSliceByKeyTag(t *testing.T) {
+	dst := pod{
+		Containers: []container{{Name: "app", Image: "app:1"}, {Name: "db", Image: "db:1", Port: 5432}},
+	}
+	src := pod{
+		Containers: []container{{Name: "db", Image: "db:2"}, {Name: "app", Port: 80}, {Name: "cache", Image: "redis"}},
+	}
+	expected := pod{
+		Containers: []container{{Name: "app", Image: "app:1", Port: 80}, {Name: "db", Image: "db:1", Port: 5432}, {Name: "cache", Image: "redis"}},
+	}
+	if err := Merge(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Test failed:\ngot  :\n%+v\n\nwant :\n%+v\n\n", dst, expected)
+	}
+	expected.Containers[1].Image = "db:2"
+	if err := Merge(&dst, src, WithOverride); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Test failed:\ngot  :\n%+v\n\nwant :\n%+v\n\n", dst, expected)
+	}
+}
+
+func TestMergeSliceByKeyOption(t *testing.T) {
+	app := &container{Name: "app", Image: "app:1"}
+	dst := pod{Sidecars: []*container{app}}
+	src := pod{Sidecars: []*container{{Name: "app", Image: "app:2", Port: 80}, {Name: "proxy"}}}
+	if err := Merge(&dst, src, WithOverride, WithSliceKey("Name")); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst.Sidecars) != 2 {
+		t.Fatalf("unexpected sidecars: %+v", dst.Sidecars)
+	}
+	if dst.Sidecars[0] != app {
+		t.Fatalf("matching pointer element should have been merged in place")
+	}
+	if *app != (container{Name: "app", Image: "app:2", Port: 80}) {
+		t.Fatalf("matching element not merged in properly: %+v", *app)
+	}
+	if dst.Sidecars[1].Name != "proxy" {
+		t.Fatalf("unmatched element not appended: %+v", dst.Sidecars[1])
+	}
+}
+
+func TestMergeSliceByKeyLeavesSrcUntouched(t *testing.T) {
+	dst := pod{Sidecars: []*container{{Name: "app"}}}
+	src := pod{Sidecars: []*container{{Name: "proxy", Image: "envoy"}, {Name: "proxy", Port: 80}}}
+	if err := Merge(&dst, src, WithSliceKey("Name")); err != nil {
+		t.Fatal(err)
+	}
+	if *src.Sidecars[0] != (container{Name: "proxy", Image: "envoy"}) {
+		t.Fatalf("src element merged into: %+v", *src.Sidecars[0])
+	}
+	if len(dst.Sidecars) != 3 {
+		t.Fatalf("unmatched elements not appended: %+v", dst.Sidecars)
+	}
+}
+
+func TestMergeSliceStrategies(t *testing.T) {
+	cases := []struct {
+		name     string
+		strategy SliceStrategy
+		dst, src []simpleTest
+		expected []simpleTest
+	}{
+		{"append", SliceAppend, []simpleTest{{1}, {2}}, []simpleTest{{2}, {3}}, []simpleTest{{1}, {2}, {2}, {3}}},
+		{"replace", SliceReplace, []simpleTest{{1}, {2}}, []simpleTest{{2}, {3}}, []simpleTest{{2}, {3}}},
+		{"union", SliceUnion, []simpleTest{{1}, {2}}, []simpleTest{{2}, {3}, {3}}, []simpleTest{{1}, {2}, {3}}},
+		{"by index", SliceByIndex, []simpleTest{{1}, {}}, []simpleTest{{5}, {6}, {7}}, []simpleTest{{1}, {6}, {7}}},
+	}
+	for _, c := range cases {
+		dst := moreComplextText{Lt: c.dst}
+		src := moreComplextText{Lt: c.src}
+		if err := Merge(&dst, src, WithSliceStrategy(c.strategy)); err != nil {
+			t.Fatalf("%s merge got error: %v", c.name, err)
+		}
+		if !reflect.DeepEqual(dst.Lt, c.expected) {
+			t.Errorf("%s merge got %+v expected %+v", c.name, dst.Lt, c.expected)
+		}
+	}
+}
+
+func TestMergeSliceUnionIsIdempotent(t *testing.T) {
+	defaults := sliceTest{[]int{1, 2, 3}}
+	dst := sliceTest{[]int{1}}
+	for i := 0; i < 3; i++ {
+		if err := Merge(&dst, defaults, WithSliceStrategy(SliceUnion)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(dst.S, []int{1, 2, 3}) {
+		t.Fatalf("elements duplicated: %v", dst.S)
+	}
+}
+
+func TestMergeSliceUnionWithEqual(t *testing.T) {
+	dst := []container{{Name: "app", Image: "app:1"}}
+	src := []container{{Name: "app", Image: "app:2"}, {Name: "db"}}
+	sameName := func(a, b interface{}) bool {
+		return a.(container).Name == b.(container).Name
+	}
+	m := map[string][]container{"pod": dst}
+	if err := Merge(&m, map[string][]container{"pod": src}, WithSliceStrategy(SliceUnion), WithSliceEqual(sameName)); err != nil {
+		t.Fatal(err)
+	}
+	expected := []container{{Name: "app", Image: "app:1"}, {Name: "db"}}
+	if !reflect.DeepEqual(m["pod"], expected) {
+		t.Fatalf("got %+v expected %+v", m["pod"], expected)
+	}
+}
+
+func TestMergeSliceStrategyInMapWithOverride(t *testing.T) {
+	type mii map[interface{}]interface{}
+	type is []interface{}
+	dst := mii{"key": is{"one", "two"}, "other": "a"}
+	src := mii{"key": is{"two", "three"}, "other": "b"}
+	expected := mii{"key": is{"one", "two", "three"}, "other": "b"}
+	if err := Merge(&dst, src, WithOverride, WithSliceStrategy(SliceUnion)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("got %+v expected %+v", dst, expected)
+	}
+}
+
+func TestMergeSliceStrategyTags(t *testing.T) {
+	type tagged struct {
+		Union []int `mergo:"union"`
+		Index []int `mergo:"index"`
+	}
+	dst := tagged{[]int{1, 2}, []int{1, 0}}
+	src := tagged{[]int{2, 3}, []int{5, 6, 7}}
+	expected := tagged{[]int{1, 2, 3}, []int{1, 6, 7}}
+	if err := Merge(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("got %+v expected %+v", dst, expected)
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2013 Dario Castañé. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mergo
+
+import (
+	"reflect"
+	"strings"
+)
+
+// tagName is the struct tag key read by Mergo.
+const tagName = "mergo"
+
+// Options understood in the mergo struct tag. They are separated by commas,
+// e.g. `mergo:"replace"` or `mergo:"deep,append"`.
+const (
+	// tagSkip leaves the field untouched.
+	tagSkip = "skip"
+	// tagReplace sets the field to the src value when it isn't empty,
+	// without merging it recursively.
+	tagReplace = "replace"
+	// tagAppend appends slices even when overriding.
+	tagAppend = "append"
+	// tagUnion appends src's slice elements not already in dst.
+	tagUnion = "union"
+	// tagIndex merges slices element by element.
+	tagIndex = "index"
+	// tagDeep merges pointers and map values recursively even when
+	// overriding, instead of replacing them.
+	tagDeep = "deep"
+	// tagKey merges slices of structs by the named field, e.g. `mergo:"key=Name"`.
+	tagKey = "key="
+	// tagMapKey names the field's key in maps, e.g. `mergo:"name=server_type"`.
+	tagMapKey = "name="
+	// tagSquash maps the fields of an embedded struct as if they were fields
+	// of the embedding one. It is also understood in the tag named by
+	// config.TagName, e.g. `json:",squash"`.
+	tagSquash = "squash"
+)
+
+// fieldConfig returns the configuration to merge field with, derived from
+// config and the field's mergo tag. skip reports whether the field must be
+// left untouched.
+func fieldConfig(field reflect.StructField, config *Config) (c *Config, skip bool) {
+	tag, ok := field.Tag.Lookup(tagName)
+	if !ok || tag == "" {
+		return config, false
+	}
+	fc := *config
+	for _, opt := range strings.Split(tag, ",") {
+		opt = strings.TrimSpace(opt)
+		if strings.HasPrefix(opt, tagKey) {
+			fc.SliceKey = strings.TrimPrefix(opt, tagKey)
+			continue
+		}
+		switch opt {
+		case tagSkip:
+			return nil, true
+		case tagReplace:
+			fc.replace = true
+		case tagAppend:
+			fc.SliceStrategy = SliceAppend
+		case tagUnion:
+			fc.SliceStrategy = SliceUnion
+		case tagIndex:
+			fc.SliceStrategy = SliceByIndex
+		case tagDeep:
+			fc.deep = true
+		}
+	}
+	return &fc, false
+}
+
+// mapKey returns the key of field in maps, and whether it must be left out
+// of them when empty. ok is false if the field must not be mapped at all.
+// The key is taken from the field's mergo tag name option, or else from the
+// tag named by config.TagName, following encoding/json conventions, or else
+// from the field's name, as set by config's KeyNamer.
+func mapKey(field reflect.StructField, config *Config) (key string, omitEmpty, ok bool) {
+	if !isExported(field) {
+		return "", false, false
+	}
+	if _, skip := fieldConfig(field, config); skip {
+		return "", false, false
+	}
+	key, omitEmpty, _, skip := taggedKey(field, config)
+	if skip {
+		return "", false, false
+	}
+	if key == "" {
+		key = keyNamer(config).Key(field.Name)
+	}
+	return key, omitEmpty, true
+}
+
+// taggedKey returns the key set for field in its tags, if any, and whether
+// the field must not be mapped, as a tag of "-" means. As in encoding/json,
+// a tag of "-," sets the key "-" instead.
+func taggedKey(field reflect.StructField, config *Config) (key string, omitEmpty, explicit, skip bool) {
+	for _, opt := range strings.Split(field.Tag.Get(tagName), ",") {
+		if opt = strings.TrimSpace(opt); strings.HasPrefix(opt, tagMapKey) {
+			key = strings.TrimPrefix(opt, tagMapKey)
+		}
+	}
+	if config.TagName == "" || config.TagName == tagName {
+		// The mergo tag only sets keys with its name option.
+		return key, false, key != "", false
+	}
+	tag, ok := field.Tag.Lookup(config.TagName)
+	if !ok {
+		return key, false, key != "", false
+	}
+	opts := strings.Split(tag, ",")
+	for _, opt := range opts[1:] {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	if key != "" {
+		return key, omitEmpty, true, false
+	}
+	if opts[0] == "-" && len(opts) == 1 {
+		return "", false, false, true
+	}
+	return opts[0], omitEmpty, opts[0] != "", false
+}
+
+// squashed reports whether the fields of field, an embedded struct or
+// pointer to struct, must be mapped as fields of the embedding struct.
+func squashed(field reflect.StructField, config *Config) bool {
+	if !field.Anonymous || !isExported(field) || indirectType(field.Type).Kind() != reflect.Struct {
+		return false
+	}
+	if _, skip := fieldConfig(field, config); skip {
+		return false
+	}
+	if hasOption(strings.Split(field.Tag.Get(tagName), ","), tagSquash) {
+		return true
+	}
+	if config.TagName != "" {
+		if opts := strings.Split(field.Tag.Get(config.TagName), ","); hasOption(opts[1:], tagSquash) {
+			return true
+		}
+	}
+	_, _, explicit, skip := taggedKey(field, config)
+	return config.Squash && !explicit && !skip
+}
+
+func hasOption(opts []string, option string) bool {
+	for _, opt := range opts {
+		if strings.TrimSpace(opt) == option {
+			return true
+		}
+	}
+	return false
+}
+
+// mapFields returns the fields of struct type t to map, with the fields of
+// squashed embedded structs in place of them. Their Index is relative to t.
+// As with promoted fields, a field hides those deeper with the same key.
+func mapFields(t reflect.Type, config *Config) []reflect.StructField {
+	var fields []reflect.StructField
+	keys := make(map[string]bool)
+	seen := map[reflect.Type]bool{t: true}
+	for level := []reflect.StructField{{Type: t}}; len(level) > 0; {
+		var next []reflect.StructField
+		var levelKeys []string
+		for _, parent := range level {
+			pt := indirectType(parent.Type)
+			for i, n := 0, pt.NumField(); i < n; i++ {
+				field := pt.Field(i)
+				field.Index = append(append([]int(nil), parent.Index...), i)
+				if squashed(field, config) {
+					if et := indirectType(field.Type); !seen[et] {
+						seen[et] = true
+						next = append(next, field)
+					}
+					continue
+				}
+				key, _, ok := mapKey(field, config)
+				if !ok || keys[key] {
+					continue
+				}
+				levelKeys = append(levelKeys, key)
+				fields = append(fields, field)
+			}
+		}
+		for _, key := range levelKeys {
+			keys[key] = true
+		}
+		level = next
+	}
+	return fields
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+// fieldByKey returns the field of struct type t whose map key is key.
+// Fields without a key set in their tags also match keys config's KeyNamer
+// matches with their name, as fields promoted from embedded structs do.
+func fieldByKey(t reflect.Type, key string, config *Config) (reflect.StructField, bool) {
+	namer := keyNamer(config)
+	var byName *reflect.StructField
+	fields := mapFields(t, config)
+	for i := range fields {
+		field := fields[i]
+		k, _, _ := mapKey(field, config)
+		if k == key {
+			return field, true
+		}
+		if _, _, explicit, _ := taggedKey(field, config); !explicit && byName == nil && namer.Match(key, field.Name) {
+			byName = &field
+		}
+	}
+	if byName != nil {
+		return *byName, true
+	}
+	match := func(name string) bool {
+		return namer.Match(key, name)
+	}
+	if field, ok := t.FieldByNameFunc(match); ok && len(field.Index) > 1 {
+		// As above, fields left out of maps or with a key of their own
+		// don't match by name.
+		if _, _, ok := mapKey(field, config); ok {
+			if _, _, explicit, _ := taggedKey(field, config); !explicit {
+				return field, true
+			}
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,84 @@
+package mergo
+
+import (
+	"reflect"
+	"testing"
+)
+
+type taggedStruct struct {
+	Name     string
+	Hosts    []string `mergo:"replace"`
+	Ports    []int    `mergo:"append"`
+	Secret   string   `mergo:"skip"`
+	Nested   *simpleTest
+	DeepNest *simpleTest `mergo:"deep"`
+}
+
+func TestMergeTags(t *testing.T) {
+	dst := taggedStruct{
+		Hosts:    []string{"a"},
+		Ports:    []int{1},
+		Nested:   &simpleTest{1},
+		DeepNest: &simpleTest{1},
+	}
+	src := taggedStruct{
+		Name:     "foo",
+		Hosts:    []string{"b", "c"},
+		Ports:    []int{2},
+		Secret:   "bar",
+		Nested:   &simpleTest{2},
+		DeepNest: &simpleTest{2},
+	}
+	nested, deepNest := dst.Nested, dst.DeepNest
+	expected := taggedStruct{
+		Name:     "foo",
+		Hosts:    []string{"b", "c"},
+		Ports:    []int{1, 2},
+		Nested:   &simpleTest{2},
+		DeepNest: &simpleTest{2},
+	}
+	if err := Merge(&dst, src, WithOverride); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Test failed:\ngot  :\n%+v\n\nwant :\n%+v\n\n", dst, expected)
+	}
+	if dst.Nested == nested {
+		t.Fatalf("dst.Nested should have been replaced by src.Nested")
+	}
+	if dst.DeepNest != deepNest {
+		t.Fatalf("dst.DeepNest should have been merged in place")
+	}
+}
+
+func TestMergeReplaceTagWithoutOverride(t *testing.T) {
+	dst := taggedStruct{Name: "foo", Hosts: []string{"a"}}
+	src := taggedStruct{Name: "bar", Hosts: []string{"b"}}
+	if err := Merge(&dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Name != "foo" {
+		t.Fatalf("dst.Name overwritten unexpectedly: %s", dst.Name)
+	}
+	if !reflect.DeepEqual(dst.Hosts, []string{"b"}) {
+		t.Fatalf("dst.Hosts not replaced: %v", dst.Hosts)
+	}
+}
+
+func TestMapSkipTag(t *testing.T) {
+	src := taggedStruct{Name: "foo", Secret: "bar"}
+	m := make(map[string]interface{})
+	if err := Map(&m, src); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m["secret"]; ok {
+		t.Fatalf("skipped field mapped unexpectedly: %v", m)
+	}
+	dst := taggedStruct{}
+	if err := Map(&dst, map[string]interface{}{"name": "foo", "secret": "bar"}); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Secret != "" {
+		t.Fatalf("skipped field merged unexpectedly: dst.Secret(%s)", dst.Secret)
+	}
+}